@@ -0,0 +1,185 @@
+// Copyright (c) 2024-2026 Carsen Klock under MIT License
+// adaptive.go - adaptive sampling for the expensive collectors: getProcessList
+// (shells out to `ps`) and getNetDiskMetrics (gopsutil). The cheap IOReport
+// power/thermal sampler in collectMetrics always runs at the base --interval;
+// adaptiveSampler instead decides, once per base tick, whether this tick
+// should also run those two. It keeps an EWMA of total package watts and
+// peak per-core CPU%%, and backs the expensive interval off by doubling
+// (up to --max-interval) after several consecutive idle ticks, snapping back
+// to --min-interval the instant either EWMA crosses its high threshold or
+// the thermal state leaves nominal, so a wake-from-idle is never stuck
+// behind a stale backoff.
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	adaptiveLowWattsThreshold  = 3.0  // package watts EWMA below this counts toward idle
+	adaptiveLowCoreThreshold   = 20.0 // peak per-core %% EWMA below this counts toward idle
+	adaptiveIdleTicksToBackoff = 5    // consecutive idle ticks before doubling the multiplier
+	adaptiveEWMAAlpha          = 0.3
+)
+
+var (
+	adaptiveFlag  bool
+	minIntervalMS int
+	maxIntervalMS int
+)
+
+// adaptiveSampler is shared between the UI goroutine (the fast CPU%% tick,
+// which also drives getProcessList) and collectMetrics (which drives
+// getNetDiskMetrics); each tracks its own tick count against the same
+// current multiplier so neither loop's cadence depends on the other's.
+type adaptiveSampler struct {
+	mu sync.Mutex
+
+	enabled                                  bool
+	minMultiplier, maxMultiplier, multiplier int
+
+	wattsEWMA, coreEWMA float64
+	ewmaInit            bool
+	idleStreak          int
+
+	uiTicks, netdiskTicks int
+}
+
+func newAdaptiveSampler(enabled bool, minMultiplier, maxMultiplier int) *adaptiveSampler {
+	if minMultiplier < 1 {
+		minMultiplier = 1
+	}
+	if maxMultiplier < minMultiplier {
+		maxMultiplier = minMultiplier
+	}
+	return &adaptiveSampler{
+		enabled:       enabled,
+		minMultiplier: minMultiplier,
+		maxMultiplier: maxMultiplier,
+		multiplier:    minMultiplier,
+	}
+}
+
+// updateMultiplier folds in one base tick's package watts, peak per-core
+// CPU%%, and whether the thermal state is nominal, then adjusts the
+// multiplier. Called once per base tick, from the UI goroutine.
+func (a *adaptiveSampler) updateMultiplier(packageWatts, peakCorePct float64, thermalNominal bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if !a.enabled {
+		return
+	}
+
+	if !a.ewmaInit {
+		a.wattsEWMA, a.coreEWMA, a.ewmaInit = packageWatts, peakCorePct, true
+	} else {
+		a.wattsEWMA += adaptiveEWMAAlpha * (packageWatts - a.wattsEWMA)
+		a.coreEWMA += adaptiveEWMAAlpha * (peakCorePct - a.coreEWMA)
+	}
+
+	idle := a.wattsEWMA < adaptiveLowWattsThreshold && a.coreEWMA < adaptiveLowCoreThreshold && thermalNominal
+	if !idle {
+		a.idleStreak = 0
+		a.multiplier = a.minMultiplier
+		return
+	}
+
+	a.idleStreak++
+	if a.idleStreak >= adaptiveIdleTicksToBackoff {
+		a.idleStreak = 0
+		if next := a.multiplier * 2; next <= a.maxMultiplier {
+			a.multiplier = next
+		}
+	}
+}
+
+func (a *adaptiveSampler) currentMultiplier() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.multiplier
+}
+
+// shouldRunUI reports whether this UI tick should also run the expensive
+// process-list collector, advancing the UI loop's own tick count.
+func (a *adaptiveSampler) shouldRunUI() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.uiTicks++
+	return a.uiTicks%a.multiplier == 0
+}
+
+// shouldRunNetDisk is collectMetrics's equivalent of shouldRunUI, against
+// its own independent tick count.
+func (a *adaptiveSampler) shouldRunNetDisk() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.netdiskTicks++
+	return a.netdiskTicks%a.multiplier == 0
+}
+
+// effectiveInterval reports the current process/net/disk sampling interval,
+// for the cpuCoreWidget title indicator and the help text's static summary.
+func (a *adaptiveSampler) effectiveInterval() time.Duration {
+	return time.Duration(updateInterval*a.currentMultiplier()) * time.Millisecond
+}
+
+// resetToBase snaps the multiplier straight back to minMultiplier and clears
+// the idle streak, bypassing the normal one-non-idle-tick-at-a-time decay;
+// called on any keypress so interacting with mactop while it's backed off
+// doesn't leave the UI feeling laggy for several ticks.
+func (a *adaptiveSampler) resetToBase() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.idleStreak = 0
+	a.multiplier = a.minMultiplier
+}
+
+// currentSampleIntervalMS reports the effective process/net/disk sampling
+// interval in milliseconds, for mactop_sample_interval_ms - updateInterval
+// itself when adaptive sampling is off or not yet set up.
+func currentSampleIntervalMS() int {
+	if adaptiveSamplerState == nil {
+		return updateInterval
+	}
+	return updateInterval * adaptiveSamplerState.currentMultiplier()
+}
+
+// adaptiveSamplerState is nil until adaptiveSampler setup runs in main(), so
+// every call site below guards with a nil check and behaves as "always run"
+// until then (matches the pre-adaptive, always-sample behavior).
+var adaptiveSamplerState *adaptiveSampler
+
+func setupAdaptiveSampler() {
+	if maxIntervalMS <= 0 {
+		maxIntervalMS = updateInterval * 8
+	}
+	if minIntervalMS <= 0 {
+		minIntervalMS = updateInterval
+	}
+	minMultiplier := minIntervalMS / updateInterval
+	maxMultiplier := maxIntervalMS / updateInterval
+	adaptiveSamplerState = newAdaptiveSampler(adaptiveFlag, minMultiplier, maxMultiplier)
+}
+
+// adaptiveStatusText summarizes --adaptive/--min-interval/--max-interval for
+// the static help text built once at startup in setupUI, which runs before
+// setupAdaptiveSampler fills in minIntervalMS/maxIntervalMS defaults - so
+// this recomputes the same defaults rather than reading them.
+func adaptiveStatusText() string {
+	if !adaptiveFlag {
+		return "Disabled"
+	}
+	minMS, maxMS := minIntervalMS, maxIntervalMS
+	if minMS <= 0 {
+		minMS = updateInterval
+	}
+	if maxMS <= 0 {
+		maxMS = updateInterval * 8
+	}
+	return fmt.Sprintf("Enabled (%s-%s for process/net/disk sampling)",
+		time.Duration(minMS)*time.Millisecond,
+		time.Duration(maxMS)*time.Millisecond,
+	)
+}
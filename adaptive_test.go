@@ -0,0 +1,116 @@
+// Copyright (c) 2024-2026 Carsen Klock under MIT License
+package main
+
+import "testing"
+
+func TestAdaptiveSamplerBacksOffAfterIdleStreak(t *testing.T) {
+	a := newAdaptiveSampler(true, 1, 8)
+
+	for i := 0; i < adaptiveIdleTicksToBackoff-1; i++ {
+		a.updateMultiplier(0, 0, true)
+		if got := a.currentMultiplier(); got != 1 {
+			t.Fatalf("tick %d: currentMultiplier() = %d, want 1 (not backed off yet)", i, got)
+		}
+	}
+
+	a.updateMultiplier(0, 0, true)
+	if got := a.currentMultiplier(); got != 2 {
+		t.Fatalf("currentMultiplier() after %d idle ticks = %d, want 2", adaptiveIdleTicksToBackoff, got)
+	}
+}
+
+func TestAdaptiveSamplerCapsAtMaxMultiplier(t *testing.T) {
+	a := newAdaptiveSampler(true, 1, 2)
+
+	for i := 0; i < adaptiveIdleTicksToBackoff*4; i++ {
+		a.updateMultiplier(0, 0, true)
+	}
+
+	if got := a.currentMultiplier(); got != 2 {
+		t.Errorf("currentMultiplier() = %d, want capped at maxMultiplier 2", got)
+	}
+}
+
+func TestAdaptiveSamplerResetsOnNonIdleTick(t *testing.T) {
+	a := newAdaptiveSampler(true, 1, 8)
+
+	for i := 0; i < adaptiveIdleTicksToBackoff; i++ {
+		a.updateMultiplier(0, 0, true)
+	}
+	if got := a.currentMultiplier(); got != 2 {
+		t.Fatalf("currentMultiplier() = %d, want 2 before the non-idle tick", got)
+	}
+
+	a.updateMultiplier(adaptiveLowWattsThreshold+1, 0, true)
+	if got := a.currentMultiplier(); got != 1 {
+		t.Errorf("currentMultiplier() after a non-idle tick = %d, want reset to minMultiplier 1", got)
+	}
+}
+
+func TestAdaptiveSamplerNonNominalThermalCountsAsNonIdle(t *testing.T) {
+	a := newAdaptiveSampler(true, 1, 8)
+
+	for i := 0; i < adaptiveIdleTicksToBackoff*2; i++ {
+		a.updateMultiplier(0, 0, false)
+	}
+
+	if got := a.currentMultiplier(); got != 1 {
+		t.Errorf("currentMultiplier() = %d, want 1 (non-nominal thermal state should never back off)", got)
+	}
+}
+
+func TestAdaptiveSamplerDisabledNeverChangesMultiplier(t *testing.T) {
+	a := newAdaptiveSampler(false, 1, 8)
+
+	for i := 0; i < adaptiveIdleTicksToBackoff*2; i++ {
+		a.updateMultiplier(0, 0, true)
+	}
+
+	if got := a.currentMultiplier(); got != 1 {
+		t.Errorf("currentMultiplier() = %d, want 1 (disabled sampler shouldn't back off)", got)
+	}
+}
+
+func TestAdaptiveSamplerResetToBase(t *testing.T) {
+	a := newAdaptiveSampler(true, 1, 8)
+	for i := 0; i < adaptiveIdleTicksToBackoff; i++ {
+		a.updateMultiplier(0, 0, true)
+	}
+	if got := a.currentMultiplier(); got != 2 {
+		t.Fatalf("currentMultiplier() = %d, want 2 before resetToBase", got)
+	}
+
+	a.resetToBase()
+	if got := a.currentMultiplier(); got != 1 {
+		t.Errorf("currentMultiplier() after resetToBase() = %d, want 1", got)
+	}
+}
+
+func TestShouldRunUIFiresEveryMultiplierTicks(t *testing.T) {
+	a := newAdaptiveSampler(true, 1, 8)
+	a.multiplier = 3
+
+	var got []bool
+	for i := 0; i < 6; i++ {
+		got = append(got, a.shouldRunUI())
+	}
+
+	want := []bool{false, false, true, false, false, true}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("shouldRunUI() tick %d = %v, want %v", i+1, got[i], want[i])
+		}
+	}
+}
+
+func TestNewAdaptiveSamplerClampsMultiplierBounds(t *testing.T) {
+	a := newAdaptiveSampler(true, 0, 0)
+	if a.minMultiplier != 1 || a.maxMultiplier != 1 {
+		t.Errorf("newAdaptiveSampler(true, 0, 0) = {min:%d max:%d}, want {min:1 max:1}", a.minMultiplier, a.maxMultiplier)
+	}
+
+	a = newAdaptiveSampler(true, 5, 2)
+	if a.maxMultiplier != 5 {
+		t.Errorf("newAdaptiveSampler(true, 5, 2).maxMultiplier = %d, want 5 (clamped up to minMultiplier)", a.maxMultiplier)
+	}
+}
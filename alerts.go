@@ -0,0 +1,637 @@
+// Copyright (c) 2024-2026 Carsen Klock under MIT License
+// alerts.go - threshold-based alerting: rules loaded from
+// ~/.mactop/thresholds.toml (a small subset of TOML, not the full spec),
+// ~/.mactop/thresholds.yaml, and/or ~/.config/mactop/alerts.yaml (the latter
+// two share a YAML parser, with a single-line "expr" like
+// "soc_temp > 95 for 30s" instead of separate metric/op/threshold/for keys)
+// are checked against each sampling tick, with "cpu", "soc_temp", "mem_used",
+// "swap_used", "power_total", "thermal_state", "disk_free", "disk_read_kbps",
+// "disk_write_kbps", "proc_cpu", and "proc_rss" as recognized metrics. A
+// firing rule is appended to an in-memory ring buffer backing the "Alerts"
+// widget (toggle with 'a'), logged as text to ~/.mactop/mactop.log and as one
+// JSON object per line to ~/.mactop/events.log, counted in mactop_alerts_total
+// and mactop_threshold_events_total (an alias, for dashboards expecting that
+// name), and reflected in the mactop_alert_firing gauge and the red alertBar
+// status line. A rule's "notify" field selects additional, rate-limited
+// sinks: "desktop" for an osascript notification banner, "webhook:<url>" to
+// POST the alert as JSON, or "command:<shell command>" to run a shell
+// command with the alert templated into ALERT_* env vars.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	ui "github.com/gizak/termui/v3"
+	w "github.com/gizak/termui/v3/widgets"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// thermalStateOrdinals mirrors the states slice in getThermalStateString, so
+// thresholds.toml can write "thermal_state>=Serious" instead of a bare number.
+var thermalStateOrdinals = map[string]float64{
+	"Nominal": 0, "Fair": 1, "Serious": 2, "Critical": 3,
+}
+
+// alertRule is one [[rule]] block from thresholds.toml, or one "- expr: ..."
+// entry from alerts.yaml.
+type alertRule struct {
+	Name         string
+	Metric       string // cpu, soc_temp, thermal_state, mem_used, power_total, disk_free, proc_cpu, proc_rss
+	Op           string // ">", ">=", "<", "<="
+	Threshold    float64
+	Clear        float64       // hysteresis: must cross back past Clear before re-firing; defaults to 10% off Threshold
+	MinFor       time.Duration // must stay past Threshold this long before firing ("for"), to avoid flapping
+	ResolveAfter time.Duration // must stay cleared this long before actually resolving ("resolve_after"); 0 resolves immediately
+	Sinks        []string      // from "notify": any of "log" (default), "desktop", "webhook:<url>", "command:<shell command>"
+}
+
+// ruleState tracks one rule's (or, for per-process rules, one rule+process
+// pair's) firing history.
+type ruleState struct {
+	firing     bool
+	exceededAt time.Time
+	clearedAt  time.Time
+}
+
+var (
+	alertRules  []alertRule
+	ruleStates  = map[string]*ruleState{}
+	alertLog    []AlertRecord
+	alertMu     sync.Mutex
+	alertsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "mactop_alerts_total",
+			Help: "Count of threshold-rule firings, by rule name.",
+		},
+		[]string{"rule"},
+	)
+	alertFiring = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "mactop_alert_firing",
+			Help: "1 if a threshold rule is currently firing, 0 if resolved, by rule name.",
+		},
+		[]string{"rule"},
+	)
+	// thresholdEventsTotal is an alias of alertsTotal under the name some
+	// dashboards expect from crunchstat-style threshold reporters; both
+	// increment together in fireAlert.
+	thresholdEventsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "mactop_threshold_events_total",
+			Help: "Count of threshold-rule firings, by rule name. Alias of mactop_alerts_total.",
+		},
+		[]string{"rule"},
+	)
+	alertsWidget    = newAlertsWidget()
+	alertBar        = newAlertBar()
+	showAlerts      bool
+	alertLogPath    string
+	eventLogPath    string
+	maxAlertLines   = 200
+	lastNotifyAt    = map[string]time.Time{}
+	notifyRateLimit = 60 * time.Second
+)
+
+// AlertRecord is one firing, either logged to mactop.log or shown in the
+// Alerts widget.
+type AlertRecord struct {
+	Time    time.Time
+	Rule    string
+	Metric  string
+	Value   float64
+	Message string
+}
+
+func newAlertsWidget() *w.List {
+	l := w.NewList()
+	l.Title = "Alerts" // set again from tr("widget.alerts.title") in setupUI, once translations are loaded
+	return l
+}
+
+// newAlertBar builds the compact, always-visible status line a layout can
+// place with the "alertbar" widget token, distinct from the full alertsWidget
+// (bound to 'a') which shows the scrollable history. It renders nothing
+// while no rule is firing and a red one-line summary while any rule is.
+func newAlertBar() *w.Paragraph {
+	p := w.NewParagraph()
+	p.Title = "Status"
+	p.TextStyle = ui.NewStyle(ui.ColorRed)
+	return p
+}
+
+// updateAlertBar refreshes alertBar from the currently-firing rules in
+// ruleStates. Called after every fireAlert and every resolve, so it never
+// lags the Prometheus mactop_alert_firing gauges by more than one tick.
+func updateAlertBar() {
+	alertMu.Lock()
+	var firing []string
+	for key, state := range ruleStates {
+		if state.firing {
+			firing = append(firing, key)
+		}
+	}
+	alertMu.Unlock()
+
+	if statusBarEnabled {
+		alertBar.Text = renderStatusBar(firing)
+		return
+	}
+
+	if len(firing) == 0 {
+		alertBar.Text = ""
+		return
+	}
+	alertBar.Text = "ALERT: " + strings.Join(firing, ", ")
+}
+
+// loadThresholds reads ~/.mactop/thresholds.toml and ~/.config/mactop/
+// alerts.yaml, if present, and concatenates whatever rules either defines.
+// Parse errors in an individual rule are logged and that rule is skipped
+// rather than aborting the whole file.
+func loadThresholds() {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return
+	}
+	alertLogPath = filepath.Join(homeDir, ".mactop", "mactop.log")
+	eventLogPath = filepath.Join(homeDir, ".mactop", "events.log")
+
+	if data, err := os.ReadFile(filepath.Join(homeDir, ".mactop", "thresholds.toml")); err == nil {
+		alertRules = append(alertRules, parseThresholds(string(data))...)
+	}
+	if data, err := os.ReadFile(filepath.Join(homeDir, ".mactop", "thresholds.yaml")); err == nil {
+		alertRules = append(alertRules, parseAlertsYAML(string(data))...)
+	}
+	if data, err := os.ReadFile(filepath.Join(homeDir, ".config", "mactop", "alerts.yaml")); err == nil {
+		alertRules = append(alertRules, parseAlertsYAML(string(data))...)
+	}
+}
+
+// parseThresholds implements a deliberately small subset of TOML: [[rule]]
+// array-of-table headers followed by bare key = value lines. No nested
+// tables, no multi-line strings, no inline arrays/tables.
+func parseThresholds(content string) []alertRule {
+	var rules []alertRule
+	var current map[string]string
+	flush := func() {
+		if current == nil {
+			return
+		}
+		rule, err := buildRule(current)
+		if err != nil {
+			stderrLogger.Printf("Skipping invalid threshold rule: %v\n", err)
+		} else {
+			rules = append(rules, rule)
+		}
+		current = nil
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if line == "[[rule]]" {
+			flush()
+			current = map[string]string{}
+			continue
+		}
+		if current == nil {
+			continue // stray key outside any [[rule]] block
+		}
+		idx := strings.Index(line, "=")
+		if idx == -1 {
+			continue
+		}
+		key := strings.TrimSpace(line[:idx])
+		value := strings.TrimSpace(line[idx+1:])
+		value = strings.Trim(value, `"`)
+		current[key] = value
+	}
+	flush()
+	return rules
+}
+
+func buildRule(fields map[string]string) (alertRule, error) {
+	name := fields["name"]
+	metric := fields["metric"]
+	op := fields["op"]
+	if name == "" || metric == "" || op == "" {
+		return alertRule{}, fmt.Errorf("rule missing name/metric/op: %v", fields)
+	}
+
+	threshold, err := parseThresholdValue(metric, fields["threshold"])
+	if err != nil {
+		return alertRule{}, fmt.Errorf("rule %q: %v", name, err)
+	}
+
+	rule := alertRule{Name: name, Metric: metric, Op: op, Threshold: threshold}
+
+	if clearStr, ok := fields["clear"]; ok {
+		clear, err := parseThresholdValue(metric, clearStr)
+		if err != nil {
+			return alertRule{}, fmt.Errorf("rule %q: invalid clear: %v", name, err)
+		}
+		rule.Clear = clear
+	} else if op == "!=" {
+		rule.Clear = threshold // recovers once value is back to exactly Threshold
+	} else if strings.HasPrefix(op, ">") {
+		rule.Clear = threshold * 0.9
+	} else {
+		rule.Clear = threshold * 1.1
+	}
+
+	if forStr, ok := fields["for"]; ok {
+		d, err := time.ParseDuration(forStr)
+		if err != nil {
+			return alertRule{}, fmt.Errorf("rule %q: invalid for: %v", name, err)
+		}
+		rule.MinFor = d
+	}
+
+	if resolveStr, ok := fields["resolve_after"]; ok {
+		d, err := time.ParseDuration(resolveStr)
+		if err != nil {
+			return alertRule{}, fmt.Errorf("rule %q: invalid resolve_after: %v", name, err)
+		}
+		rule.ResolveAfter = d
+	}
+
+	if notify, ok := fields["notify"]; ok {
+		for _, sink := range strings.Split(notify, ",") {
+			if sink = strings.TrimSpace(sink); sink != "" {
+				rule.Sinks = append(rule.Sinks, sink)
+			}
+		}
+	}
+	return rule, nil
+}
+
+// exprPattern matches alerts.yaml's one-line rule expression, e.g.
+// "soc_temp > 95 for 30s" or "thermal_state != nominal". The "for" clause is
+// optional; when absent MinFor is 0, matching thresholds.toml's default.
+var exprPattern = regexp.MustCompile(`^(\S+)\s*(>=|<=|!=|>|<)\s*(\S+?)(?:\s+for\s+(\S+))?$`)
+
+// parseAlertsYAML implements a deliberately small subset of YAML, just
+// enough for a flat "rules:" list of "- name/expr/resolve_after/notify"
+// mappings; the same scoping thresholds.toml's parser already applies to
+// TOML. No nesting, anchors, or flow style.
+func parseAlertsYAML(content string) []alertRule {
+	var rules []alertRule
+	var current map[string]string
+	flush := func() {
+		if current == nil {
+			return
+		}
+		rule, err := buildRuleFromYAML(current)
+		if err != nil {
+			stderrLogger.Printf("Skipping invalid alerts.yaml rule: %v\n", err)
+		} else {
+			rules = append(rules, rule)
+		}
+		current = nil
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || trimmed == "rules:" {
+			continue
+		}
+		if strings.HasPrefix(trimmed, "- ") {
+			flush()
+			current = map[string]string{}
+			trimmed = strings.TrimPrefix(trimmed, "- ")
+		}
+		if current == nil {
+			continue // stray line outside any "- " entry
+		}
+		idx := strings.Index(trimmed, ":")
+		if idx == -1 {
+			continue
+		}
+		key := strings.TrimSpace(trimmed[:idx])
+		value := strings.TrimSpace(trimmed[idx+1:])
+		value = strings.Trim(value, `"'`)
+		current[key] = value
+	}
+	flush()
+	return rules
+}
+
+// buildRuleFromYAML turns one alerts.yaml entry's "expr" field (plus
+// name/resolve_after/notify) into an alertRule, reusing buildRule for
+// everything past expression-parsing so both formats share one code path.
+func buildRuleFromYAML(fields map[string]string) (alertRule, error) {
+	name := fields["name"]
+	expr := fields["expr"]
+	if name == "" || expr == "" {
+		return alertRule{}, fmt.Errorf("rule missing name/expr: %v", fields)
+	}
+	m := exprPattern.FindStringSubmatch(expr)
+	if m == nil {
+		return alertRule{}, fmt.Errorf("rule %q: unparseable expr %q", name, expr)
+	}
+	tomlFields := map[string]string{
+		"name":      name,
+		"metric":    m[1],
+		"op":        m[2],
+		"threshold": m[3],
+	}
+	if m[4] != "" {
+		tomlFields["for"] = m[4]
+	}
+	if v, ok := fields["resolve_after"]; ok {
+		tomlFields["resolve_after"] = v
+	}
+	if v, ok := fields["notify"]; ok {
+		tomlFields["notify"] = v
+	}
+	return buildRule(tomlFields)
+}
+
+func parseThresholdValue(metric, raw string) (float64, error) {
+	if metric == "thermal_state" {
+		for name, v := range thermalStateOrdinals {
+			if strings.EqualFold(name, raw) {
+				return v, nil
+			}
+		}
+	}
+	return strconv.ParseFloat(raw, 64)
+}
+
+// evaluateAlerts checks every rule whose metric is present in values against
+// its current value, applying hysteresis (Clear) and minimum-duration
+// (MinFor) before firing. Per-process rules (proc_cpu, proc_rss) are checked
+// against every process in processes instead of values.
+func evaluateAlerts(values map[string]float64, processes []ProcessMetrics) {
+	now := time.Now()
+	for _, rule := range alertRules {
+		switch rule.Metric {
+		case "proc_cpu", "proc_rss":
+			for _, p := range processes {
+				value := p.CPU
+				if rule.Metric == "proc_rss" {
+					value = float64(p.RSS) / 1024 / 1024 // RSS is sampled in KB; compare in GB
+				}
+				key := rule.Name + ":" + p.Command
+				evaluateOne(rule, key, value, now, fmt.Sprintf(" (pid %d, %s)", p.PID, p.Command))
+			}
+		default:
+			value, ok := values[rule.Metric]
+			if !ok {
+				continue
+			}
+			evaluateOne(rule, rule.Name, value, now, "")
+		}
+	}
+}
+
+func evaluateOne(rule alertRule, stateKey string, value float64, now time.Time, suffix string) {
+	state, ok := ruleStates[stateKey]
+	if !ok {
+		state = &ruleState{}
+		ruleStates[stateKey] = state
+	}
+
+	exceeds := compareThreshold(rule.Op, value, rule.Threshold)
+	cleared := compareThreshold(reverseOp(rule.Op), value, rule.Clear)
+
+	if !state.firing {
+		if !exceeds {
+			state.exceededAt = time.Time{}
+			return
+		}
+		if state.exceededAt.IsZero() {
+			state.exceededAt = now
+		}
+		if now.Sub(state.exceededAt) < rule.MinFor {
+			return
+		}
+		state.firing = true
+		state.clearedAt = time.Time{}
+		fireAlert(rule, value, suffix)
+	} else if !cleared {
+		state.clearedAt = time.Time{}
+	} else {
+		if state.clearedAt.IsZero() {
+			state.clearedAt = now
+		}
+		if now.Sub(state.clearedAt) < rule.ResolveAfter {
+			return
+		}
+		state.firing = false
+		state.exceededAt = time.Time{}
+		state.clearedAt = time.Time{}
+		alertFiring.With(prometheus.Labels{"rule": rule.Name}).Set(0)
+		updateAlertBar()
+	}
+}
+
+func compareThreshold(op string, value, threshold float64) bool {
+	switch op {
+	case ">":
+		return value > threshold
+	case ">=":
+		return value >= threshold
+	case "<":
+		return value < threshold
+	case "<=":
+		return value <= threshold
+	case "!=":
+		return value != threshold
+	case "==":
+		return value == threshold
+	default:
+		return false
+	}
+}
+
+// reverseOp gives the "has recovered" comparison for a rule's trigger op,
+// e.g. ">" fires above Threshold and clears once back below Clear, and
+// "!=" (e.g. "thermal_state != nominal") clears once back at Clear exactly.
+func reverseOp(op string) string {
+	switch op {
+	case ">", ">=":
+		return "<"
+	case "!=":
+		return "=="
+	default:
+		return ">"
+	}
+}
+
+func fireAlert(rule alertRule, value float64, suffix string) {
+	record := AlertRecord{
+		Time:    time.Now(),
+		Rule:    rule.Name,
+		Metric:  rule.Metric,
+		Value:   value,
+		Message: fmt.Sprintf("%s: %s %s %.1f (value %.1f)%s", rule.Name, rule.Metric, rule.Op, rule.Threshold, value, suffix),
+	}
+
+	alertMu.Lock()
+	alertLog = append(alertLog, record)
+	if len(alertLog) > maxAlertLines {
+		alertLog = alertLog[len(alertLog)-maxAlertLines:]
+	}
+	rows := make([]string, len(alertLog))
+	for i, r := range alertLog {
+		rows[i] = fmt.Sprintf("[%s] %s", r.Time.Format("15:04:05"), r.Message)
+	}
+	alertsWidget.Rows = rows
+	alertMu.Unlock()
+
+	alertsTotal.With(prometheus.Labels{"rule": rule.Name}).Inc()
+	thresholdEventsTotal.With(prometheus.Labels{"rule": rule.Name}).Inc()
+	alertFiring.With(prometheus.Labels{"rule": rule.Name}).Set(1)
+	appendAlertLogLine(record)
+	appendEventLogLine(record)
+	dispatchSinks(rule, record)
+	updateAlertBar()
+}
+
+// dispatchSinks notifies rule.Sinks (defaulting to just the log/widget/
+// counter bookkeeping fireAlert already did above if Sinks is empty).
+// External sinks (desktop, webhook) are rate-limited per rule name so a
+// rule that clears and re-fires in quick succession doesn't spam them;
+// the in-memory log and Prometheus counter above are never rate-limited.
+func dispatchSinks(rule alertRule, record AlertRecord) {
+	if len(rule.Sinks) == 0 {
+		return
+	}
+
+	alertMu.Lock()
+	last, seen := lastNotifyAt[rule.Name]
+	limited := seen && time.Since(last) < notifyRateLimit
+	if !limited {
+		lastNotifyAt[rule.Name] = record.Time
+	}
+	alertMu.Unlock()
+	if limited {
+		return
+	}
+
+	for _, sink := range rule.Sinks {
+		switch {
+		case sink == "log":
+			// already logged to mactop.log and the Alerts widget above.
+		case sink == "desktop":
+			sendDesktopNotification(record)
+		case strings.HasPrefix(sink, "webhook:"):
+			sendWebhook(strings.TrimPrefix(sink, "webhook:"), record)
+		case strings.HasPrefix(sink, "command:"):
+			runAlertCommand(strings.TrimPrefix(sink, "command:"), record)
+		default:
+			stderrLogger.Printf("Unknown alert sink %q on rule %q\n", sink, rule.Name)
+		}
+	}
+}
+
+// sendDesktopNotification shows a macOS notification banner via osascript,
+// the same mechanism terminal-notifier wraps.
+func sendDesktopNotification(record AlertRecord) {
+	script := fmt.Sprintf("display notification %q with title \"mactop alert\"", record.Message)
+	if err := exec.Command("osascript", "-e", script).Run(); err != nil {
+		stderrLogger.Printf("Failed to show desktop notification: %v\n", err)
+	}
+}
+
+// sendWebhook POSTs the alert as JSON to url.
+func sendWebhook(url string, record AlertRecord) {
+	body, err := json.Marshal(record)
+	if err != nil {
+		stderrLogger.Printf("Failed to marshal alert webhook payload: %v\n", err)
+		return
+	}
+	client := http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		stderrLogger.Printf("Failed to POST alert webhook to %s: %v\n", url, err)
+		return
+	}
+	resp.Body.Close()
+}
+
+// runAlertCommand runs command through the user's shell, with the alert
+// templated into ALERT_NAME/ALERT_METRIC/ALERT_VALUE/ALERT_MESSAGE env vars
+// rather than interpolated into the command string, so a message containing
+// shell metacharacters can't inject into the command.
+func runAlertCommand(command string, record AlertRecord) {
+	cmd := exec.Command("/bin/sh", "-c", command)
+	cmd.Env = append(os.Environ(),
+		"ALERT_NAME="+record.Rule,
+		"ALERT_METRIC="+record.Metric,
+		fmt.Sprintf("ALERT_VALUE=%g", record.Value),
+		"ALERT_MESSAGE="+record.Message,
+	)
+	if err := cmd.Run(); err != nil {
+		stderrLogger.Printf("Alert command %q failed: %v\n", command, err)
+	}
+}
+
+func appendAlertLogLine(record AlertRecord) {
+	if alertLogPath == "" {
+		return
+	}
+	f, err := os.OpenFile(alertLogPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	fmt.Fprintf(f, "[%s] %s\n", record.Time.Format(time.RFC3339), record.Message)
+}
+
+// appendEventLogLine appends record to ~/.mactop/events.log as one JSON
+// object per line, a structured counterpart to mactop.log's human-readable
+// text for anything that wants to tail and parse firings programmatically
+// (e.g. feeding them into another alerting pipeline) without a log scraper.
+func appendEventLogLine(record AlertRecord) {
+	if eventLogPath == "" {
+		return
+	}
+	f, err := os.OpenFile(eventLogPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	if err := json.NewEncoder(f).Encode(record); err != nil {
+		stderrLogger.Printf("Failed to append event log line: %v\n", err)
+	}
+}
+
+// toggleAlertsWidget swaps the grid to a full-screen Alerts view, bound to
+// the 'a' key, the same way toggleHelpMenu swaps to the help screen.
+func toggleAlertsWidget() {
+	showAlerts = !showAlerts
+	if showAlerts {
+		newGrid := ui.NewGrid()
+		newGrid.Set(
+			ui.NewRow(1.0,
+				ui.NewCol(1.0, alertsWidget),
+			),
+		)
+		termWidth, termHeight := ui.TerminalDimensions()
+		newGrid.SetRect(0, 0, termWidth, termHeight)
+		grid = newGrid
+	} else {
+		applyLayoutPreset(currentLayoutPreset)
+	}
+	ui.Clear()
+	ui.Render(grid)
+}
@@ -0,0 +1,157 @@
+// Copyright (c) 2024-2026 Carsen Klock under MIT License
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCompareThreshold(t *testing.T) {
+	tests := []struct {
+		op            string
+		value, thresh float64
+		want          bool
+	}{
+		{">", 5, 3, true},
+		{">", 3, 3, false},
+		{">=", 3, 3, true},
+		{"<", 2, 3, true},
+		{"<=", 3, 3, true},
+		{"!=", 1, 3, true},
+		{"!=", 3, 3, false},
+		{"==", 3, 3, true},
+		{"bogus", 3, 3, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.op, func(t *testing.T) {
+			if got := compareThreshold(tt.op, tt.value, tt.thresh); got != tt.want {
+				t.Errorf("compareThreshold(%q, %v, %v) = %v, want %v", tt.op, tt.value, tt.thresh, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReverseOp(t *testing.T) {
+	tests := []struct{ op, want string }{
+		{">", "<"},
+		{">=", "<"},
+		{"!=", "=="},
+		{"<", ">"},
+		{"<=", ">"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.op, func(t *testing.T) {
+			if got := reverseOp(tt.op); got != tt.want {
+				t.Errorf("reverseOp(%q) = %q, want %q", tt.op, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildRuleDefaultsClearAndResolveAfter(t *testing.T) {
+	rule, err := buildRule(map[string]string{
+		"name": "cpu-hot", "metric": "cpu", "op": ">", "threshold": "90",
+	})
+	if err != nil {
+		t.Fatalf("buildRule() error = %v", err)
+	}
+	if rule.Clear != 81 {
+		t.Errorf("rule.Clear = %v, want 81 (90%% of threshold for a \">\" rule)", rule.Clear)
+	}
+	if rule.ResolveAfter != 0 {
+		t.Errorf("rule.ResolveAfter = %v, want 0 when resolve_after is unset", rule.ResolveAfter)
+	}
+}
+
+func TestBuildRuleParsesResolveAfter(t *testing.T) {
+	rule, err := buildRule(map[string]string{
+		"name": "cpu-hot", "metric": "cpu", "op": ">", "threshold": "90", "resolve_after": "30s",
+	})
+	if err != nil {
+		t.Fatalf("buildRule() error = %v", err)
+	}
+	if rule.ResolveAfter != 30*time.Second {
+		t.Errorf("rule.ResolveAfter = %v, want 30s", rule.ResolveAfter)
+	}
+}
+
+func TestBuildRuleRejectsInvalidResolveAfter(t *testing.T) {
+	if _, err := buildRule(map[string]string{
+		"name": "cpu-hot", "metric": "cpu", "op": ">", "threshold": "90", "resolve_after": "not-a-duration",
+	}); err == nil {
+		t.Error("buildRule() error = nil, want error for invalid resolve_after")
+	}
+}
+
+func TestBuildRuleFromYAMLParsesExprAndResolveAfter(t *testing.T) {
+	rule, err := buildRuleFromYAML(map[string]string{
+		"name": "soc-hot", "expr": "soc_temp > 95 for 30s", "resolve_after": "1m",
+	})
+	if err != nil {
+		t.Fatalf("buildRuleFromYAML() error = %v", err)
+	}
+	if rule.Metric != "soc_temp" || rule.Op != ">" || rule.Threshold != 95 {
+		t.Errorf("rule = %+v, want metric=soc_temp op=> threshold=95", rule)
+	}
+	if rule.MinFor != 30*time.Second {
+		t.Errorf("rule.MinFor = %v, want 30s", rule.MinFor)
+	}
+	if rule.ResolveAfter != time.Minute {
+		t.Errorf("rule.ResolveAfter = %v, want 1m", rule.ResolveAfter)
+	}
+}
+
+// TestEvaluateOneHysteresis walks a rule through exceed -> fire -> dip
+// (not cleared, stays firing) -> clear -> resolve, checking that ResolveAfter
+// holds the rule firing until it has stayed cleared long enough.
+func TestEvaluateOneHysteresis(t *testing.T) {
+	defer func() { ruleStates = map[string]*ruleState{} }()
+	ruleStates = map[string]*ruleState{}
+
+	rule := alertRule{
+		Name: "cpu-hot", Metric: "cpu", Op: ">", Threshold: 90, Clear: 80,
+		ResolveAfter: time.Minute,
+	}
+	key := "test:cpu-hot"
+	now := time.Now()
+
+	evaluateOne(rule, key, 95, now, "")
+	if !ruleStates[key].firing {
+		t.Fatal("rule should be firing once value exceeds Threshold")
+	}
+
+	evaluateOne(rule, key, 85, now.Add(time.Second))
+	if !ruleStates[key].firing {
+		t.Fatal("rule should still be firing at a value between Clear and Threshold")
+	}
+
+	evaluateOne(rule, key, 70, now.Add(2*time.Second))
+	if !ruleStates[key].firing {
+		t.Fatal("rule should still be firing immediately after dropping below Clear, until ResolveAfter elapses")
+	}
+
+	evaluateOne(rule, key, 70, now.Add(2*time.Second+30*time.Millisecond).Add(time.Minute))
+	if ruleStates[key].firing {
+		t.Error("rule should have resolved once it stayed below Clear for ResolveAfter")
+	}
+}
+
+func TestEvaluateOneMinForDelaysFiring(t *testing.T) {
+	defer func() { ruleStates = map[string]*ruleState{} }()
+	ruleStates = map[string]*ruleState{}
+
+	rule := alertRule{Name: "cpu-hot", Metric: "cpu", Op: ">", Threshold: 90, Clear: 80, MinFor: time.Minute}
+	key := "test:minfor"
+	now := time.Now()
+
+	evaluateOne(rule, key, 95, now)
+	if ruleStates[key].firing {
+		t.Fatal("rule should not fire before MinFor has elapsed")
+	}
+
+	evaluateOne(rule, key, 95, now.Add(2*time.Minute))
+	if !ruleStates[key].firing {
+		t.Error("rule should fire once it has stayed exceeded for MinFor")
+	}
+}
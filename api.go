@@ -0,0 +1,175 @@
+// Copyright (c) 2024-2026 Carsen Klock under MIT License
+// api.go - the HTTP/JSON and Server-Sent Events API behind --prometheus and
+// --api-socket: GET /api/v1/snapshot (one merged CPU/GPU/SoC/net/disk/process
+// point), GET /api/v1/stream (the same snapshot pushed once per tick as SSE),
+// and GET /api/v1/processes?sort=cpu&limit=20. This lets a menu-bar app,
+// Raycast extension, or web dashboard consume mactop's samples without
+// re-implementing IOReport parsing, and lets --exporter/--prometheus double
+// as a real data source for long-running daemons rather than just /metrics.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// APISnapshot is the payload for /api/v1/snapshot and each /api/v1/stream
+// frame: the latest point history.go's ring buffer already tracks, plus the
+// process list as of the last rendered tick (sortedProcesses), so polling
+// this endpoint costs nothing beyond what the TUI was already sampling.
+type APISnapshot struct {
+	HistoryPoint
+	Processes []ProcessMetrics `json:"processes"`
+}
+
+func latestSnapshot() APISnapshot {
+	historyMu.Lock()
+	var point HistoryPoint
+	if n := len(historyRing); n > 0 {
+		point = historyRing[n-1]
+	}
+	historyMu.Unlock()
+	return APISnapshot{HistoryPoint: point, Processes: sortedProcesses}
+}
+
+// startAPIServer builds the API mux around registry (the same
+// *prometheus.Registry newAPIRegistry built, shared with --exporter so both
+// paths publish identical mactop_* series) and serves it on whichever of
+// port (TCP, ":"+port) and socketPath (a Unix domain socket) are non-empty;
+// either, both, or (from the call site) neither may be set. apiToken, if
+// set, gates the TCP listener only - the Unix socket's reach is already
+// limited to local processes that can open the path, so it is never
+// token-checked.
+func startAPIServer(port, socketPath string, registry *prometheus.Registry) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+	mux.HandleFunc("/history.json", historyHandler)
+	mux.HandleFunc("/api/v1/snapshot", snapshotHandler)
+	mux.HandleFunc("/api/v1/stream", streamHandler)
+	mux.HandleFunc("/api/v1/processes", processesHandler)
+
+	if port != "" {
+		var handler http.Handler = mux
+		if apiToken != "" {
+			handler = requireAPIToken(mux)
+		}
+		go func() {
+			if err := http.ListenAndServe(":"+port, handler); err != nil {
+				stderrLogger.Printf("Failed to start API server: %v\n", err)
+			}
+		}()
+	}
+
+	if socketPath != "" {
+		go func() {
+			os.Remove(socketPath) // clear a stale socket from an unclean previous exit
+			listener, err := net.Listen("unix", socketPath)
+			if err != nil {
+				stderrLogger.Printf("Failed to bind API socket %s: %v\n", socketPath, err)
+				return
+			}
+			if err := http.Serve(listener, mux); err != nil {
+				stderrLogger.Printf("API socket server error: %v\n", err)
+			}
+		}()
+	}
+}
+
+// requireAPIToken wraps next with a bearer-token check against apiToken.
+func requireAPIToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		if auth != "Bearer "+apiToken {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// snapshotHandler serves GET /api/v1/snapshot.
+func snapshotHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(latestSnapshot())
+}
+
+// streamHandler serves GET /api/v1/stream as Server-Sent Events, pushing one
+// "data: <snapshot JSON>\n\n" frame per sampling tick until the client
+// disconnects.
+func streamHandler(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	ticker := time.NewTicker(time.Duration(updateInterval) * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+			body, err := json.Marshal(latestSnapshot())
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "data: %s\n\n", body)
+			flusher.Flush()
+		}
+	}
+}
+
+// processesHandler serves GET /api/v1/processes?sort=<column>&limit=<n>,
+// sorting a copy of sortedProcesses (the last rendered list, same source the
+// TUI's own sort keybinds use) by one of the process list's own column
+// names, case-insensitively, descending. limit defaults to returning all
+// processes; sort defaults to "cpu".
+func processesHandler(w http.ResponseWriter, r *http.Request) {
+	sortBy := strings.ToLower(r.URL.Query().Get("sort"))
+	if sortBy == "" {
+		sortBy = "cpu"
+	}
+	processes := append([]ProcessMetrics{}, sortedProcesses...)
+	sort.Slice(processes, func(i, j int) bool {
+		switch sortBy {
+		case "pid":
+			return processes[i].PID > processes[j].PID
+		case "mem":
+			return processes[i].Memory > processes[j].Memory
+		case "power":
+			return processes[i].PowerWatts > processes[j].PowerWatts
+		case "energy":
+			return processes[i].EnergyMJ > processes[j].EnergyMJ
+		case "virt":
+			return processes[i].VSZ > processes[j].VSZ
+		case "res":
+			return processes[i].RSS > processes[j].RSS
+		default:
+			return processes[i].CPU > processes[j].CPU
+		}
+	})
+
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if limit, err := strconv.Atoi(limitStr); err == nil && limit >= 0 && limit < len(processes) {
+			processes = processes[:limit]
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(processes)
+}
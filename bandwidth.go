@@ -0,0 +1,78 @@
+// Copyright (c) 2024-2026 Carsen Klock under MIT License
+// bandwidth.go - human-readable, auto-scaling network/disk rate formatting,
+// selectable via --bandwidth={bits,bytes,auto} and --unit={si,iec} (mirroring
+// gotop's -B flag), with a runtime 'u' keybind to cycle --bandwidth modes.
+package main
+
+import "fmt"
+
+// bandwidthMode controls whether rates render in bits or bytes per second.
+type bandwidthMode string
+
+const (
+	bandwidthBytes bandwidthMode = "bytes"
+	bandwidthBits  bandwidthMode = "bits"
+	bandwidthAuto  bandwidthMode = "auto" // same scaling as bytes, kept distinct for --bandwidth=auto users
+)
+
+var (
+	bandwidthFlag = bandwidthBytes
+	unitFlag      = "si" // "si" (1000-based, B/KB/MB/GB) or "iec" (1024-based, B/KiB/MiB/GiB)
+)
+
+func parseBandwidthFlag(s string) (bandwidthMode, error) {
+	switch bandwidthMode(s) {
+	case bandwidthBytes, bandwidthBits, bandwidthAuto:
+		return bandwidthMode(s), nil
+	default:
+		return bandwidthBytes, fmt.Errorf("unsupported --bandwidth value %q (want bits, bytes, or auto)", s)
+	}
+}
+
+func parseUnitFlag(s string) (string, error) {
+	switch s {
+	case "si", "iec":
+		return s, nil
+	default:
+		return "si", fmt.Errorf("unsupported --unit value %q (want si or iec)", s)
+	}
+}
+
+// cycleBandwidthMode advances bandwidthFlag between bytes/bits/auto, bound to
+// the 'u' key.
+func cycleBandwidthMode() {
+	switch bandwidthFlag {
+	case bandwidthBytes:
+		bandwidthFlag = bandwidthBits
+	case bandwidthBits:
+		bandwidthFlag = bandwidthAuto
+	default:
+		bandwidthFlag = bandwidthBytes
+	}
+}
+
+// formatRate auto-scales a rate given in KB/s (as NetDiskMetrics already
+// samples it) up through B/s, KB/s, MB/s, GB/s per bandwidthFlag/unitFlag,
+// e.g. "12.3MB/s", "98.4Mb/s" (bits), or "512B/s".
+func formatRate(kbPerSec float64) string {
+	base := 1000.0
+	prefixes := []string{"", "K", "M", "G"}
+	if unitFlag == "iec" {
+		base = 1024.0
+		prefixes = []string{"", "Ki", "Mi", "Gi"}
+	}
+
+	value := kbPerSec * 1024.0 // NetDiskMetrics rates are sampled in KB/s (1024-based)
+	unitChar := "B"
+	if bandwidthFlag == bandwidthBits {
+		value *= 8
+		unitChar = "b"
+	}
+
+	idx := 0
+	for value >= base && idx < len(prefixes)-1 {
+		value /= base
+		idx++
+	}
+	return fmt.Sprintf("%.1f%s%s/s", value, prefixes[idx], unitChar)
+}
@@ -0,0 +1,199 @@
+// Copyright (c) 2024-2026 Carsen Klock under MIT License
+// batch.go - --batch mode: skip ui.Init() and the termui event loop, sample
+// through the same collectMetrics path the interactive UI uses, and emit one
+// JSON or CSV record per tick to stdout. Meant for scripts/CI, the way
+// crunchstat or `perf stat` output is consumed.
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	batchFlag      bool
+	batchOutput    = "json" // "json" or "csv"
+	batchSamples   int      // 0 = unbounded
+	batchDuration  time.Duration
+	batchTopN      = 5
+	batchFieldsArg string // raw --fields value, comma-separated; empty = all fields
+)
+
+// batchFieldOrder is every field --batch can emit, in the order a bare
+// --batch (no --fields) prints them.
+var batchFieldOrder = []string{
+	"timestamp", "cpu_percent", "cpu_watts", "gpu_watts", "gpu_percent",
+	"ane_watts", "dram_watts", "package_watts", "thermal_state",
+	"mem_used_gb", "mem_total_gb", "net_in_kbps", "net_out_kbps",
+	"disk_read_kbps", "disk_write_kbps", "top_processes",
+}
+
+// batchRecord holds one tick's worth of sampled values as a field->value map
+// so --fields can select a subset identically for both JSON and CSV output.
+type batchRecord map[string]interface{}
+
+func parseBatchFields(arg string) []string {
+	if arg == "" {
+		return batchFieldOrder
+	}
+	var fields []string
+	for _, f := range strings.Split(arg, ",") {
+		f = strings.TrimSpace(f)
+		if f != "" {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// runBatch drives collectMetrics the same way the TUI does, but writes
+// structured records to stdout instead of rendering widgets, for up to
+// batchSamples ticks or batchDuration, whichever comes first (0 means
+// unbounded for that dimension).
+func runBatch() {
+	if err := initSocMetrics(); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to initialize metrics: %v\n", err)
+		os.Exit(1)
+	}
+	defer cleanupSocMetrics()
+
+	fields := parseBatchFields(batchFieldsArg)
+
+	cpuMetricsChan := make(chan CPUMetrics, 1)
+	gpuMetricsChan := make(chan GPUMetrics, 1)
+	netdiskMetricsChan := make(chan NetDiskMetrics, 1)
+	batteryMetricsChan := make(chan BatteryMetrics, 1)
+	done := make(chan struct{})
+	go collectMetrics(done, cpuMetricsChan, gpuMetricsChan, netdiskMetricsChan, batteryMetricsChan)
+
+	var lastCPU CPUMetrics
+	var lastGPU GPUMetrics
+	var lastNetDisk NetDiskMetrics
+
+	var csvWriter *csv.Writer
+	jsonEncoder := json.NewEncoder(os.Stdout)
+	if batchOutput == "csv" {
+		csvWriter = csv.NewWriter(os.Stdout)
+		csvWriter.Write(fields)
+		defer csvWriter.Flush()
+	}
+
+	ticker := time.NewTicker(time.Duration(updateInterval) * time.Millisecond)
+	defer ticker.Stop()
+
+	deadline := time.Time{}
+	if batchDuration > 0 {
+		deadline = time.Now().Add(batchDuration)
+	}
+
+	samplesWritten := 0
+	for {
+		select {
+		case m := <-cpuMetricsChan:
+			lastCPU = m
+		case m := <-gpuMetricsChan:
+			lastGPU = m
+		case m := <-netdiskMetricsChan:
+			lastNetDisk = m
+		case <-ticker.C:
+			recordSamples(lastCPU, lastGPU)
+			record := buildBatchRecord(lastCPU, lastGPU, lastNetDisk)
+			writeBatchRecord(record, fields, jsonEncoder, csvWriter)
+			samplesWritten++
+			if batchSamples > 0 && samplesWritten >= batchSamples {
+				close(done)
+				return
+			}
+			if !deadline.IsZero() && time.Now().After(deadline) {
+				close(done)
+				return
+			}
+		}
+	}
+}
+
+func buildBatchRecord(cpuMetrics CPUMetrics, gpuMetrics GPUMetrics, netdiskMetrics NetDiskMetrics) batchRecord {
+	percentages, err := GetCPUPercentages()
+	var cpuPercent float64
+	if err == nil && len(percentages) > 0 {
+		var total float64
+		for _, p := range percentages {
+			total += p
+		}
+		cpuPercent = total / float64(len(percentages))
+	}
+	thermalStr, _ := getThermalStateString()
+	mem := getMemoryMetrics()
+
+	processes := getProcessList()
+	attributeProcessPower(processes)
+	sort.Slice(processes, func(i, j int) bool { return processes[i].CPU > processes[j].CPU })
+	if len(processes) > batchTopN {
+		processes = processes[:batchTopN]
+	}
+
+	return batchRecord{
+		"timestamp":       time.Now().Format(time.RFC3339),
+		"cpu_percent":     cpuPercent,
+		"cpu_watts":       cpuMetrics.CPUW,
+		"gpu_watts":       cpuMetrics.GPUW,
+		"gpu_percent":     gpuMetrics.Active,
+		"ane_watts":       cpuMetrics.ANEW,
+		"dram_watts":      cpuMetrics.DRAMW,
+		"package_watts":   cpuMetrics.PackageW,
+		"thermal_state":   thermalStr,
+		"mem_used_gb":     float64(mem.Used) / 1024 / 1024 / 1024,
+		"mem_total_gb":    float64(mem.Total) / 1024 / 1024 / 1024,
+		"net_in_kbps":     netdiskMetrics.InBytesPerSec,
+		"net_out_kbps":    netdiskMetrics.OutBytesPerSec,
+		"disk_read_kbps":  netdiskMetrics.ReadKBytesPerSec,
+		"disk_write_kbps": netdiskMetrics.WriteKBytesPerSec,
+		"top_processes":   processes,
+	}
+}
+
+func writeBatchRecord(record batchRecord, fields []string, jsonEncoder *json.Encoder, csvWriter *csv.Writer) {
+	if csvWriter == nil {
+		filtered := make(batchRecord, len(fields))
+		for _, f := range fields {
+			filtered[f] = record[f]
+		}
+		if err := jsonEncoder.Encode(filtered); err != nil {
+			fmt.Fprintf(os.Stderr, "Error encoding batch record: %v\n", err)
+		}
+		return
+	}
+
+	row := make([]string, len(fields))
+	for i, f := range fields {
+		row[i] = batchFieldToString(record[f])
+	}
+	if err := csvWriter.Write(row); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing batch record: %v\n", err)
+		return
+	}
+	csvWriter.Flush()
+}
+
+// batchFieldToString renders a field value for CSV, where top_processes (a
+// []ProcessMetrics) doesn't have a natural scalar form.
+func batchFieldToString(v interface{}) string {
+	switch val := v.(type) {
+	case []ProcessMetrics:
+		parts := make([]string, len(val))
+		for i, p := range val {
+			parts[i] = fmt.Sprintf("%d:%s:%.1f%%", p.PID, p.Command, p.CPU)
+		}
+		return strings.Join(parts, "|")
+	case float64:
+		return strconv.FormatFloat(val, 'f', 2, 64)
+	default:
+		return fmt.Sprintf("%v", val)
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright (c) 2024-2026 Carsen Klock under MIT License
+// battery.go - battery and power-flow telemetry for Apple Silicon laptops
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// BatteryMetrics holds a single sample of the system battery/power-adapter state.
+type BatteryMetrics struct {
+	Present         bool    `json:"present"`
+	Charging        bool    `json:"charging"`
+	ACPower         bool    `json:"ac_power"`
+	PercentCharge   int     `json:"percent_charge"`
+	CycleCount      int     `json:"cycle_count"`
+	DesignCapacity  int     `json:"design_capacity_mah"`
+	MaxCapacity     int     `json:"max_capacity_mah"`
+	CurrentCapacity int     `json:"current_capacity_mah"`
+	AmperageMA      int     `json:"amperage_ma"`
+	VoltageMV       int     `json:"voltage_mv"`
+	WattsNow        float64 `json:"watts_now"` // + charging, - discharging
+	AdapterWatts    float64 `json:"adapter_watts"`
+	TimeRemaining   string  `json:"time_remaining"`
+	Temperature     float64 `json:"temperature_celsius"`
+}
+
+// getBatteryMetrics samples `ioreg -rn AppleSmartBattery` for the current battery
+// state. It returns Present=false (and no error) on desktops with no battery.
+func getBatteryMetrics() (BatteryMetrics, error) {
+	var m BatteryMetrics
+
+	out, err := exec.Command("ioreg", "-rn", "AppleSmartBattery").Output()
+	if err != nil {
+		return m, fmt.Errorf("failed to execute ioreg command: %v", err)
+	}
+	text := string(out)
+	if !strings.Contains(text, "AppleSmartBattery") {
+		return m, nil
+	}
+	m.Present = true
+
+	fields := map[string]*int{
+		"\"CycleCount\"":      &m.CycleCount,
+		"\"DesignCapacity\"":  &m.DesignCapacity,
+		"\"MaxCapacity\"":     &m.MaxCapacity,
+		"\"CurrentCapacity\"": &m.CurrentCapacity,
+		"\"Amperage\"":        &m.AmperageMA,
+		"\"Voltage\"":         &m.VoltageMV,
+	}
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		key := strings.TrimSpace(parts[0])
+		valStr := strings.TrimSpace(parts[1])
+		if dst, ok := fields[key]; ok {
+			// Amperage is reported as an unsigned 64-bit two's complement value
+			// when discharging, so fall back to a signed parse on overflow.
+			if v, err := strconv.Atoi(valStr); err == nil {
+				*dst = v
+			} else if v, err := strconv.ParseUint(valStr, 10, 64); err == nil {
+				*dst = int(int16(v))
+			}
+			continue
+		}
+		switch key {
+		case "\"IsCharging\"":
+			m.Charging = valStr == "Yes"
+		case "\"ExternalConnected\"":
+			m.ACPower = valStr == "Yes"
+		case "\"Temperature\"":
+			if v, err := strconv.Atoi(valStr); err == nil {
+				m.Temperature = float64(v) / 100.0
+			}
+		}
+	}
+
+	if m.MaxCapacity > 0 {
+		m.PercentCharge = (m.CurrentCapacity * 100) / m.MaxCapacity
+	}
+	if m.VoltageMV > 0 {
+		m.WattsNow = float64(m.AmperageMA) * float64(m.VoltageMV) / 1e6
+	}
+
+	if battOut, err := exec.Command("pmset", "-g", "batt").Output(); err == nil {
+		battText := string(battOut)
+		if idx := strings.Index(battText, "; "); idx != -1 {
+			rest := battText[idx+2:]
+			if semi := strings.Index(rest, ";"); semi != -1 {
+				rest = rest[:semi]
+			}
+			rest = strings.TrimSpace(rest)
+			if rest != "" && rest != "0:00" {
+				m.TimeRemaining = rest
+			}
+		}
+		m.ACPower = m.ACPower || strings.Contains(battText, "AC Power")
+	}
+
+	return m, nil
+}
+
+// getAdapterWatts parses the wattage of the connected power adapter, if any.
+func getAdapterWatts() float64 {
+	out, err := exec.Command("ioreg", "-rn", "AppleSmartChargerManager", "-a").Output()
+	if err != nil {
+		return 0
+	}
+	text := string(out)
+	idx := strings.Index(text, "AdapterDetails")
+	if idx == -1 {
+		return 0
+	}
+	rest := text[idx:]
+	wIdx := strings.Index(rest, "Watts")
+	if wIdx == -1 {
+		return 0
+	}
+	rest = rest[wIdx:]
+	var digits strings.Builder
+	seenDigit := false
+	for _, r := range rest {
+		if r >= '0' && r <= '9' {
+			digits.WriteRune(r)
+			seenDigit = true
+		} else if seenDigit {
+			break
+		}
+	}
+	watts, _ := strconv.Atoi(digits.String())
+	return float64(watts)
+}
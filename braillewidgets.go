@@ -0,0 +1,97 @@
+// Copyright (c) 2024-2026 Carsen Klock under MIT License
+// braillewidgets.go - braille-rendered drop-in replacements for termui's
+// block-character sparkline/graph, for Retina terminals that can afford the
+// extra resolution. Gated behind --render=braille (default: blocks).
+package main
+
+import (
+	"image"
+	"os"
+	"strings"
+
+	ui "github.com/gizak/termui/v3"
+
+	"github.com/context-labs/mactop/v2/internal/render"
+)
+
+// renderMode is the active rendering backend, set from --render and from
+// autodetectRenderMode when unset.
+var renderMode = "blocks"
+
+// autodetectRenderMode falls back to "blocks" when the terminal is unlikely
+// to render braille well: a non-UTF-8 locale, or no locale information at all.
+func autodetectRenderMode() string {
+	lang := os.Getenv("LANG") + os.Getenv("LC_ALL") + os.Getenv("LC_CTYPE")
+	if lang == "" || !strings.Contains(strings.ToUpper(lang), "UTF-8") {
+		return "blocks"
+	}
+	return "braille"
+}
+
+// BrailleSparkline is a termui Drawable that renders a single data series as
+// a braille line plot, giving 8x the vertical resolution of a block-character
+// Sparkline in the same cell footprint.
+type BrailleSparkline struct {
+	*ui.Block
+	Data      []float64
+	MaxVal    float64
+	LineColor ui.Color
+}
+
+func NewBrailleSparkline() *BrailleSparkline {
+	return &BrailleSparkline{
+		Block:     ui.NewBlock(),
+		LineColor: ui.ColorWhite,
+	}
+}
+
+func (s *BrailleSparkline) Draw(buf *ui.Buffer) {
+	s.Block.Draw(buf)
+	maxVal := s.MaxVal
+	if maxVal <= 0 {
+		for _, v := range s.Data {
+			if v > maxVal {
+				maxVal = v
+			}
+		}
+	}
+	if maxVal <= 0 || s.Inner.Dx() <= 0 || s.Inner.Dy() <= 0 {
+		return
+	}
+	canvas := render.NewBrailleCanvas(s.Inner.Dx(), s.Inner.Dy())
+	canvas.PlotLine(s.Data, maxVal)
+	style := ui.NewStyle(s.LineColor)
+	for y, row := range canvas.Render() {
+		for x, r := range row {
+			buf.SetCell(ui.NewCell(r, style), image.Pt(s.Inner.Min.X+x, s.Inner.Min.Y+y))
+		}
+	}
+}
+
+// BrailleGraph is the braille counterpart to a SparklineGroup: a titled block
+// holding one or more BrailleSparklines, used for the CPU/GPU utilization and
+// power history plots.
+type BrailleGraph struct {
+	*ui.Block
+	Sparklines []*BrailleSparkline
+}
+
+func NewBrailleGraph(sparklines ...*BrailleSparkline) *BrailleGraph {
+	return &BrailleGraph{
+		Block:      ui.NewBlock(),
+		Sparklines: sparklines,
+	}
+}
+
+func (g *BrailleGraph) Draw(buf *ui.Buffer) {
+	g.Block.Draw(buf)
+	if len(g.Sparklines) == 0 {
+		return
+	}
+	rowHeight := g.Inner.Dy() / len(g.Sparklines)
+	for i, s := range g.Sparklines {
+		s.Block.Border = false
+		s.SetRect(g.Inner.Min.X, g.Inner.Min.Y+i*rowHeight, g.Inner.Max.X, g.Inner.Min.Y+(i+1)*rowHeight)
+		s.Draw(buf)
+	}
+}
@@ -0,0 +1,262 @@
+// Copyright (c) 2024-2026 Carsen Klock under MIT License
+// colorscheme.go - per-widget colorscheme files, layered on top of the
+// single-color theme.go cycling. --colorscheme accepts a built-in name
+// ("default", "default-dark", "solarized", "monokai", "nord"), a scheme name
+// found under ~/.mactop/colorschemes/<name>.json, or a literal path; with
+// nothing set it falls back to ~/.config/mactop/colorscheme.json if present.
+//
+// Color values are still just colorMap names (see theme.go) for the
+// original 7-color fields, but every field below also accepts a bare
+// xterm-256 palette index as a string (e.g. "214") - termui/v3 represents
+// ui.Color as a small int that already doubles as a 256-color palette
+// index, so this is genuine extra range, not an approximation. termui v3
+// has no truecolor/RGB renderer, so a raw "#rrggbb" hex value isn't
+// supported; the practical ceiling here is the terminal's 256-color table.
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	ui "github.com/gizak/termui/v3"
+	w "github.com/gizak/termui/v3/widgets"
+)
+
+// ColorScheme assigns a color (see resolveColor) to each widget and, for the
+// CPU core bar, to each usage threshold - individually, instead of the
+// single global color applyTheme sets.
+type ColorScheme struct {
+	CPU     string `json:"cpu"`
+	GPU     string `json:"gpu"`
+	ANE     string `json:"ane"`
+	Memory  string `json:"mem"`
+	Power   string `json:"power"`
+	Net     string `json:"net"`
+	Procs   string `json:"procs"`
+	Model   string `json:"model"`
+	Battery string `json:"battery"`
+
+	Border        string `json:"border"`         // widget border color, falls back to each widget's own color above when unset
+	Title         string `json:"title"`          // widget title color, same fallback
+	SelectedFg    string `json:"selected_fg"`    // process-list selected-row foreground
+	SelectedBg    string `json:"selected_bg"`    // process-list selected-row background
+	SecondaryText string `json:"secondary_text"` // dimmer text, e.g. the CPU time/system stats widgets
+	Bracket       string `json:"bracket"`        // the "[" / "]" around CPUCoreWidget's bars, and gauge brackets
+	Background    string `json:"background"`     // reserved: termui/v3 doesn't expose a per-widget fill color to set this against
+
+	CPULow  string `json:"cpu_bar_low"`  // CPUCoreWidget bar color below cpu_bar_mid's threshold (default cyan, <30%)
+	CPUMid  string `json:"cpu_bar_mid"`  // default yellow, 30-59%
+	CPUHigh string `json:"cpu_bar_high"` // default red, >=60%
+}
+
+// activeColorScheme is the scheme applyColorScheme last applied, read by
+// CPUCoreWidget.Draw for its per-bar threshold colors since that widget
+// draws itself from a raw *ui.Buffer rather than a termui style field.
+var activeColorScheme = defaultColorScheme()
+
+func defaultColorScheme() ColorScheme {
+	return ColorScheme{
+		CPU: "green", GPU: "magenta", ANE: "cyan", Memory: "blue",
+		Power: "green", Net: "white", Procs: "green", Model: "white", Battery: "green",
+		Border: "green", Title: "green", SelectedFg: "black", SelectedBg: "green",
+		SecondaryText: "white", Bracket: "white",
+		CPULow: "cyan", CPUMid: "yellow", CPUHigh: "red",
+	}
+}
+
+// builtinColorSchemes are the named presets --colorscheme accepts without a
+// file on disk. Palette values are approximate xterm-256 stand-ins for each
+// scheme's usual look (termui v3 has no truecolor renderer - see the file
+// doc comment).
+var builtinColorSchemes = map[string]ColorScheme{
+	"default": defaultColorScheme(),
+	"default-dark": {
+		CPU: "green", GPU: "magenta", ANE: "cyan", Memory: "blue",
+		Power: "green", Net: "white", Procs: "green", Model: "white", Battery: "green",
+		Border: "white", Title: "white", SelectedFg: "black", SelectedBg: "white",
+		SecondaryText: "white", Bracket: "white",
+		CPULow: "cyan", CPUMid: "yellow", CPUHigh: "red",
+	},
+	"solarized": {
+		CPU: "yellow", GPU: "blue", ANE: "cyan", Memory: "green",
+		Power: "yellow", Net: "cyan", Procs: "green", Model: "white", Battery: "yellow",
+		Border: "cyan", Title: "yellow", SelectedFg: "black", SelectedBg: "yellow",
+		SecondaryText: "cyan", Bracket: "cyan",
+		CPULow: "cyan", CPUMid: "yellow", CPUHigh: "red",
+	},
+	"monokai": {
+		CPU: "magenta", GPU: "green", ANE: "cyan", Memory: "yellow",
+		Power: "magenta", Net: "cyan", Procs: "green", Model: "white", Battery: "magenta",
+		Border: "magenta", Title: "magenta", SelectedFg: "black", SelectedBg: "magenta",
+		SecondaryText: "white", Bracket: "yellow",
+		CPULow: "cyan", CPUMid: "yellow", CPUHigh: "red",
+	},
+	"nord": {
+		CPU: "blue", GPU: "cyan", ANE: "cyan", Memory: "blue",
+		Power: "blue", Net: "white", Procs: "blue", Model: "white", Battery: "blue",
+		Border: "blue", Title: "blue", SelectedFg: "black", SelectedBg: "blue",
+		SecondaryText: "white", Bracket: "blue",
+		CPULow: "cyan", CPUMid: "yellow", CPUHigh: "red",
+	},
+	// auto-light is --colorscheme auto's pick when queryOSC11Background (see
+	// osc11.go) finds a light terminal background - every other scheme here
+	// uses bright foreground colors (white, cyan, yellow) that assume a dark
+	// one, so none of them are a usable fallback for this case.
+	"auto-light": {
+		CPU: "blue", GPU: "magenta", ANE: "blue", Memory: "blue",
+		Power: "red", Net: "black", Procs: "blue", Model: "black", Battery: "blue",
+		Border: "black", Title: "blue", SelectedFg: "white", SelectedBg: "blue",
+		SecondaryText: "black", Bracket: "black",
+		CPULow: "blue", CPUMid: "yellow", CPUHigh: "red",
+	},
+}
+
+// resolveAutoColorScheme implements --colorscheme auto: it queries the
+// terminal's real background over OSC 11 and picks whichever built-in
+// scheme's foreground palette is designed for that brightness, falling back
+// to "default-dark" if the terminal never replies (common over SSH/tmux
+// without OSC passthrough) or on a light/dark call it can't make.
+//
+// This intentionally stops short of synthesizing a bespoke palette by hue
+// rotation: termui/v3 has no truecolor renderer (see this file's top
+// comment), so a derived RGB color has no faithful rendering target here -
+// choosing between two already xterm-256-safe palettes is the honest
+// version of "auto" for this renderer.
+//
+// Must be called before ui.Init(): it opens its own /dev/tty handle and
+// reads the reply directly, and termbox-go's background input goroutine
+// (started by ui.Init) would otherwise be racing it for bytes off the same
+// controlling tty, routinely winning and leaving queryOSC11Background to
+// time out (see osc11.go's top comment).
+func resolveAutoColorScheme() ColorScheme {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return builtinColorSchemes["default-dark"]
+	}
+	defer tty.Close()
+
+	r, g, b, ok := queryOSC11Background(tty, 200*time.Millisecond)
+	if ok && isLightBackground(r, g, b) {
+		return builtinColorSchemes["auto-light"]
+	}
+	return builtinColorSchemes["default-dark"]
+}
+
+// resolveColor maps a ColorScheme field's value to a ui.Color: a name from
+// colorMap, a bare xterm-256 palette index (e.g. "214"), or green if name is
+// empty/unrecognized.
+func resolveColor(name string) ui.Color {
+	if c, ok := colorMap[name]; ok {
+		return c
+	}
+	if n, err := strconv.Atoi(name); err == nil && n >= 0 && n <= 255 {
+		return ui.Color(n)
+	}
+	return ui.ColorGreen
+}
+
+// colorSchemeDir is where named schemes (other than the built-ins) are
+// discovered: ~/.mactop/colorschemes/<name>.json.
+func colorSchemeDir(homeDir string) string {
+	return filepath.Join(homeDir, ".mactop", "colorschemes")
+}
+
+// loadColorSchemeFile resolves a --colorscheme value: a built-in name, a
+// "<name>.json" file under ~/.mactop/colorschemes/, a same-named file under
+// one of DataDirs() (e.g. /usr/share/mactop/colorschemes, for schemes a
+// package maintainer ships system-wide rather than per-user), or a literal
+// path. An empty nameOrPath falls back to ~/.config/mactop/colorscheme.json
+// if that exists. Returns ok=false if nothing resolves.
+func loadColorSchemeFile(nameOrPath string) (ColorScheme, bool) {
+	if nameOrPath == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return ColorScheme{}, false
+		}
+		return loadColorSchemeJSON(filepath.Join(homeDir, ".config", "mactop", "colorscheme.json"))
+	}
+	if scheme, ok := builtinColorSchemes[nameOrPath]; ok {
+		return scheme, true
+	}
+	if homeDir, err := os.UserHomeDir(); err == nil {
+		if scheme, ok := loadColorSchemeJSON(filepath.Join(colorSchemeDir(homeDir), nameOrPath+".json")); ok {
+			return scheme, true
+		}
+	}
+	for _, dataDir := range DataDirs() {
+		if scheme, ok := loadColorSchemeJSON(filepath.Join(dataDir, "colorschemes", nameOrPath+".json")); ok {
+			return scheme, true
+		}
+	}
+	return loadColorSchemeJSON(nameOrPath)
+}
+
+// loadColorSchemeJSON reads and parses one colorscheme file, seeding
+// unset fields from defaultColorScheme so a scheme only needs to override
+// what it changes.
+func loadColorSchemeJSON(path string) (ColorScheme, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ColorScheme{}, false
+	}
+	scheme := defaultColorScheme()
+	if err := json.Unmarshal(data, &scheme); err != nil {
+		return ColorScheme{}, false
+	}
+	return scheme, true
+}
+
+// applyColorScheme sets each widget's colors individually from scheme.
+func applyColorScheme(scheme ColorScheme) {
+	activeColorScheme = scheme
+	colorFor := resolveColor
+
+	setGaugeColor := func(g *w.Gauge, name string) {
+		if g == nil {
+			return
+		}
+		c := colorFor(name)
+		g.BarColor, g.BorderStyle.Fg, g.TitleStyle.Fg = c, colorFor(scheme.Border), colorFor(scheme.Title)
+	}
+	setParagraphColor := func(p *w.Paragraph, name string) {
+		if p == nil {
+			return
+		}
+		c := colorFor(name)
+		p.TextStyle, p.BorderStyle.Fg, p.TitleStyle.Fg = ui.NewStyle(c), colorFor(scheme.Border), colorFor(scheme.Title)
+	}
+
+	setGaugeColor(cpuGauge, scheme.CPU)
+	setGaugeColor(gpuGauge, scheme.GPU)
+	setGaugeColor(aneGauge, scheme.ANE)
+	setGaugeColor(memoryGauge, scheme.Memory)
+	setGaugeColor(batteryGauge, scheme.Battery)
+	setParagraphColor(PowerChart, scheme.Power)
+	setParagraphColor(NetworkInfo, scheme.Net)
+	setParagraphColor(modelText, scheme.Model)
+	setParagraphColor(cpuTimeWidget, scheme.SecondaryText)
+	setParagraphColor(systemStatsWidget, scheme.SecondaryText)
+
+	if processList != nil {
+		c := colorFor(scheme.Procs)
+		processList.TextStyle = ui.NewStyle(c)
+		processList.SelectedRowStyle = ui.NewStyle(colorFor(scheme.SelectedFg), colorFor(scheme.SelectedBg))
+		processList.BorderStyle.Fg, processList.TitleStyle.Fg = colorFor(scheme.Border), colorFor(scheme.Title)
+	}
+	if sparkline != nil {
+		c := colorFor(scheme.Power)
+		sparkline.LineColor = c
+		sparklineGroup.BorderStyle.Fg, sparklineGroup.TitleStyle.Fg = colorFor(scheme.Border), colorFor(scheme.Title)
+	}
+	if gpuSparkline != nil {
+		c := colorFor(scheme.GPU)
+		gpuSparkline.LineColor = c
+		gpuSparklineGroup.BorderStyle.Fg, gpuSparklineGroup.TitleStyle.Fg = colorFor(scheme.Border), colorFor(scheme.Title)
+	}
+	if cpuCoreWidget != nil {
+		cpuCoreWidget.BorderStyle.Fg, cpuCoreWidget.TitleStyle.Fg = colorFor(scheme.Border), colorFor(scheme.Title)
+	}
+}
@@ -2,52 +2,83 @@ package main
 
 import (
 	"encoding/json"
+	"log"
 	"os"
 	"path/filepath"
 )
 
 type AppConfig struct {
-	DefaultLayout string `json:"default_layout"`
-	Theme         string `json:"theme"`
+	DefaultLayout  string `json:"default_layout"`
+	Theme          string `json:"theme"`
+	ProcessCPUMode string `json:"process_cpu_mode"` // "normalized" (default) or "raw", see getProcessList
+	CPUGraphMode   string `json:"cpu_graph_mode"`   // "bar" (default) or "braille", see cpuGraphMode
+	StatusBar      bool   `json:"statusbar"`        // see statusBarEnabled in statusbar.go
 }
 
 var currentConfig AppConfig
 
+const configFileName = "config.json"
+
 func loadConfig() {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		currentConfig = AppConfig{DefaultLayout: "default"}
-		return
-	}
-	configPath := filepath.Join(homeDir, ".mactop", "config.json")
+	defaultConfig := AppConfig{DefaultLayout: "default", ProcessCPUMode: "normalized", CPUGraphMode: "bar"}
+	configPath := filepath.Join(ConfigDir(), configFileName)
+	migrateLegacyConfig(configPath)
 
 	file, err := os.ReadFile(configPath)
 	if err != nil {
-		currentConfig = AppConfig{DefaultLayout: "default"}
+		currentConfig = defaultConfig
 		return
 	}
 
-	err = json.Unmarshal(file, &currentConfig)
-	if err != nil {
-		currentConfig = AppConfig{DefaultLayout: "default"}
+	if err := json.Unmarshal(file, &currentConfig); err != nil {
+		currentConfig = defaultConfig
 	}
 }
 
-func saveConfig() {
+// migrateLegacyConfig moves a pre-XDG ~/.mactop/config.json to configPath
+// the first time it's found, so upgrading to a build with ConfigDir doesn't
+// reset an existing user's settings back to defaults. A no-op once
+// configPath already exists or no legacy file is present.
+func migrateLegacyConfig(configPath string) {
+	if _, err := os.Stat(configPath); err == nil {
+		return
+	}
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
 		return
 	}
-	configDir := filepath.Join(homeDir, ".mactop")
+	legacyPath := filepath.Join(legacyConfigDir(homeDir), configFileName)
+	data, err := os.ReadFile(legacyPath)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(configPath), 0755); err != nil {
+		log.Printf("Failed to create config directory for migration %s: %v\n", filepath.Dir(configPath), err)
+		return
+	}
+	if err := os.WriteFile(configPath, data, 0644); err != nil {
+		log.Printf("Failed to migrate legacy config to %s: %v\n", configPath, err)
+		return
+	}
+	os.Remove(legacyPath)
+	log.Printf("Migrated legacy config %s to %s\n", legacyPath, configPath)
+}
+
+func saveConfig() {
+	configDir := ConfigDir()
 	if err := os.MkdirAll(configDir, 0755); err != nil {
+		log.Printf("Failed to create config directory %s: %v\n", configDir, err)
 		return
 	}
-	configPath := filepath.Join(configDir, "config.json")
+	configPath := filepath.Join(configDir, configFileName)
 
 	data, err := json.MarshalIndent(currentConfig, "", "  ")
 	if err != nil {
+		log.Printf("Failed to marshal config: %v\n", err)
 		return
 	}
 
-	os.WriteFile(configPath, data, 0644)
+	if err := os.WriteFile(configPath, data, 0644); err != nil {
+		log.Printf("Failed to write config file %s: %v\n", configPath, err)
+	}
 }
@@ -0,0 +1,56 @@
+//go:build cgo && darwin
+
+// Copyright (c) 2024-2026 Carsen Klock under MIT License
+// cpuusage_cgo.go - per-core CPU tick counts via host_processor_info, the
+// same cgo/Mach API GetCPUPercentages (main.go) turns into usage
+// percentages. Split out of main.go so that file has no import "C" of its
+// own: Go's toolchain treats any file using cgo as cgo-only regardless of
+// build tags, so having it in main.go meant CGO_ENABLED=0 excluded func
+// main() itself. See cpuusage_purego.go for the CGO_ENABLED=0 sibling.
+package main
+
+/*
+#include <mach/mach_host.h>
+#include <mach/processor_info.h>
+#include <mach/mach_init.h>
+
+extern kern_return_t vm_deallocate(vm_map_t target_task, vm_address_t address, vm_size_t size);
+*/
+import "C"
+import (
+	"fmt"
+	"unsafe"
+)
+
+func GetCPUUsage() ([]CPUUsage, error) {
+	var numCPUs C.natural_t
+	var cpuLoad *C.processor_cpu_load_info_data_t
+	var cpuMsgCount C.mach_msg_type_number_t
+	host := C.mach_host_self()
+	kernReturn := C.host_processor_info(
+		host,
+		C.PROCESSOR_CPU_LOAD_INFO,
+		&numCPUs,
+		(*C.processor_info_array_t)(unsafe.Pointer(&cpuLoad)),
+		&cpuMsgCount,
+	)
+	if kernReturn != C.KERN_SUCCESS {
+		return nil, fmt.Errorf("error getting CPU info: %d", kernReturn)
+	}
+	defer C.vm_deallocate(
+		C.mach_task_self_,
+		(C.vm_address_t)(uintptr(unsafe.Pointer(cpuLoad))),
+		C.vm_size_t(cpuMsgCount)*C.sizeof_processor_cpu_load_info_data_t,
+	)
+	cpuLoadInfo := (*[1 << 30]C.processor_cpu_load_info_data_t)(unsafe.Pointer(cpuLoad))[:numCPUs:numCPUs]
+	cpuUsage := make([]CPUUsage, numCPUs)
+	for i := 0; i < int(numCPUs); i++ {
+		cpuUsage[i] = CPUUsage{
+			User:   float64(cpuLoadInfo[i].cpu_ticks[C.CPU_STATE_USER]),
+			System: float64(cpuLoadInfo[i].cpu_ticks[C.CPU_STATE_SYSTEM]),
+			Idle:   float64(cpuLoadInfo[i].cpu_ticks[C.CPU_STATE_IDLE]),
+			Nice:   float64(cpuLoadInfo[i].cpu_ticks[C.CPU_STATE_NICE]),
+		}
+	}
+	return cpuUsage, nil
+}
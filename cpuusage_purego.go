@@ -0,0 +1,29 @@
+//go:build !cgo && darwin
+
+// Copyright (c) 2024-2026 Carsen Klock under MIT License
+// cpuusage_purego.go - per-core CPU tick counts via gopsutil/v4's cpu
+// package (sysctl through golang.org/x/sys/unix, no cgo) instead of
+// host_processor_info, for CGO_ENABLED=0 builds. See cpuusage_cgo.go for the
+// cgo-path sibling; both must return CPUUsage in the same "ticks at the
+// traditional 100/sec (USER_HZ) rate" unit GetCPUPercentages expects, so
+// gopsutil's float-seconds are scaled up by 100 here.
+package main
+
+import "github.com/shirou/gopsutil/v4/cpu"
+
+func GetCPUUsage() ([]CPUUsage, error) {
+	times, err := cpu.Times(true)
+	if err != nil {
+		return nil, err
+	}
+	cpuUsage := make([]CPUUsage, len(times))
+	for i, t := range times {
+		cpuUsage[i] = CPUUsage{
+			User:   t.User * 100,
+			System: t.System * 100,
+			Idle:   t.Idle * 100,
+			Nice:   t.Nice * 100,
+		}
+	}
+	return cpuUsage, nil
+}
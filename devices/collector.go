@@ -0,0 +1,39 @@
+// Copyright (c) 2024-2026 Carsen Klock under MIT License
+// Package devices is the interface third-party samplers implement to plug
+// into mactop without forking it: battery-health probes, HID sensors, eGPU
+// stats, container runtimes, or anything else that can produce named metric
+// values on a timer. Collectors register themselves (directly, or via a
+// pluginapi.Registry.RegisterDevice call from a loaded .so) and are sampled
+// generically alongside mactop's own built-in CPU/GPU/netdisk/process/
+// thermal goroutines.
+package devices
+
+import (
+	"context"
+	"time"
+)
+
+// Collector is one pluggable sampler. Sample is called on mactop's own
+// update interval and should return within dur; a collector that can't keep
+// up should trim its own work rather than block the shared tick.
+type Collector interface {
+	// Name identifies the collector in logs and as a Prometheus metric
+	// name prefix, e.g. "battery_health" or "orbstack".
+	Name() string
+	Init() error
+	Sample(ctx context.Context, dur time.Duration) (map[string]float64, error)
+	Close() error
+}
+
+var registry []Collector
+
+// Register adds a Collector to the package-level registry. Called from
+// plugin.go's RegisterDevice on behalf of loaded .so plugins.
+func Register(c Collector) {
+	registry = append(registry, c)
+}
+
+// All returns every registered Collector, in registration order.
+func All() []Collector {
+	return registry
+}
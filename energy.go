@@ -0,0 +1,117 @@
+// Copyright (c) 2024-2026 Carsen Klock under MIT License
+// energy.go - joule accumulators layered on top of SocMetrics' instantaneous
+// watts, the same counter-vs-gauge distinction Prometheus/node_exporter
+// make: a gauge (mactop_power_watts) answers "how much right now", a
+// counter (mactop_energy_joules_total) answers "how much since start",
+// and survives rate changes since it only ever increases. Also covers
+// --attribute-pid/--attribute-cmd, which apportions the CPU domain's joules
+// to one process by its share of total CPU% each tick - GPU/ANE energy
+// can't be attributed the same way (mactop has no per-process GPU/ANE
+// counter to take a ratio of), so it's left in the unattributed remainder.
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	energyMu                                                      sync.Mutex
+	cpuEnergyJ, gpuEnergyJ, aneEnergyJ, dramEnergyJ, totalEnergyJ float64
+
+	attributePID   int
+	attributeCmdRe *regexp.Regexp
+	processEnergyJ = map[int]float64{} // pid -> attributed CPU-domain joules since start
+
+	energyJoulesTotal = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "mactop_energy_joules_total",
+			Help: "Joules accumulated since mactop started, by domain. Monotonically increasing, unlike mactop_power_watts.",
+		},
+		[]string{"domain"},
+	)
+
+	processEnergyJoulesTotal = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "mactop_process_energy_joules_total",
+			Help: "CPU-domain joules attributed to one process since start, via --attribute-pid/--attribute-cmd. GPU/ANE energy can't be attributed this way and isn't included.",
+		},
+		[]string{"pid", "comm"},
+	)
+)
+
+// accumulateEnergy adds this tick's watts * intervalSeconds to each domain's
+// running joule total, and (if --attribute-pid/--attribute-cmd selected a
+// process present in processes this tick) apportions a share of the CPU
+// domain's joules to it by that process's share of total system CPU%.
+func accumulateEnergy(m CPUMetrics, intervalSeconds float64, processes []ProcessMetrics) {
+	energyMu.Lock()
+	cpuEnergyJ += m.CPUW * intervalSeconds
+	gpuEnergyJ += m.GPUW * intervalSeconds
+	aneEnergyJ += m.ANEW * intervalSeconds
+	dramEnergyJ += m.DRAMW * intervalSeconds
+	totalEnergyJ += m.PackageW * intervalSeconds
+	energyMu.Unlock()
+
+	energyJoulesTotal.With(prometheus.Labels{"domain": "cpu"}).Set(cpuEnergyJ)
+	energyJoulesTotal.With(prometheus.Labels{"domain": "gpu"}).Set(gpuEnergyJ)
+	energyJoulesTotal.With(prometheus.Labels{"domain": "ane"}).Set(aneEnergyJ)
+	energyJoulesTotal.With(prometheus.Labels{"domain": "dram"}).Set(dramEnergyJ)
+	energyJoulesTotal.With(prometheus.Labels{"domain": "total"}).Set(totalEnergyJ)
+
+	if attributePID == 0 && attributeCmdRe == nil {
+		return
+	}
+	var totalCPU float64
+	for _, p := range processes {
+		totalCPU += p.CPU
+	}
+	if totalCPU <= 0 {
+		return
+	}
+	for _, p := range processes {
+		if !matchesAttributionTarget(p) {
+			continue
+		}
+		share := p.CPU / totalCPU
+		energyMu.Lock()
+		processEnergyJ[p.PID] += m.CPUW * intervalSeconds * share
+		joules := processEnergyJ[p.PID]
+		energyMu.Unlock()
+		processEnergyJoulesTotal.With(prometheus.Labels{
+			"pid":  fmt.Sprintf("%d", p.PID),
+			"comm": p.Command,
+		}).Set(joules)
+	}
+}
+
+func matchesAttributionTarget(p ProcessMetrics) bool {
+	if attributePID != 0 {
+		return p.PID == attributePID
+	}
+	if attributeCmdRe != nil {
+		return attributeCmdRe.MatchString(p.Command)
+	}
+	return false
+}
+
+// energyFooter renders the TUI footer line chunk4-5 asked for: a running
+// kWh estimate for total package energy, plus the attributed process's
+// running total if --attribute-pid/--attribute-cmd selected one.
+func energyFooter() string {
+	energyMu.Lock()
+	totalKWh := totalEnergyJ / 3_600_000
+	defer energyMu.Unlock()
+	footer := fmt.Sprintf("Energy since start: %.4f kWh", totalKWh)
+	if attributePID == 0 && attributeCmdRe == nil {
+		return footer
+	}
+	var attributed float64
+	for _, j := range processEnergyJ {
+		attributed += j
+	}
+	return footer + fmt.Sprintf(" | Attributed CPU energy: %.1f J (GPU/ANE unattributed)", attributed)
+}
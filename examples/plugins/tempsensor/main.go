@@ -0,0 +1,57 @@
+// Copyright (c) 2024-2026 Carsen Klock under MIT License
+// tempsensor is a worked example of a mactop plugin: it registers a
+// paragraph widget under the layout DSL name "tempsensor" showing a
+// per-sensor temperature readout, plus a Prometheus collector, a 't'
+// keybinding that refreshes it on demand, and a "tempsensor" layout preset
+// that puts the widget on the grid without the user needing a layout.conf.
+//
+// Build with:
+//
+//	go build -buildmode=plugin -o ~/.config/mactop/plugins/tempsensor.so ./examples/plugins/tempsensor
+package main
+
+import (
+	"fmt"
+
+	ui "github.com/gizak/termui/v3"
+	w "github.com/gizak/termui/v3/widgets"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/context-labs/mactop/v2/pluginapi"
+)
+
+var (
+	tempParagraph = w.NewParagraph()
+	tempGauge     = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "mactop_plugin_example_temp_celsius",
+		Help: "Example plugin sensor reading, in celsius.",
+	})
+)
+
+// Register is the ABI entrypoint mactop's plugin loader looks up by name.
+func Register(r pluginapi.Registry) {
+	tempParagraph.Title = "Example Sensor"
+	tempParagraph.BorderStyle.Fg = ui.ColorCyan
+	refresh()
+
+	r.RegisterWidget("tempsensor", tempParagraph)
+	if err := r.RegisterCollector(tempGauge); err != nil {
+		return
+	}
+	r.RegisterKeybinding("t", refresh)
+	r.RegisterLayoutPreset("tempsensor", "cpu gpu\ntempsensor\nprocs")
+
+	go func() {
+		for range r.Tick() {
+			refresh()
+		}
+	}()
+}
+
+// refresh is a stand-in for reading a real sensor; a production plugin would
+// shell out to an SMC reader or read a vendor sysfs/IOKit path here.
+func refresh() {
+	reading := 42.0
+	tempGauge.Set(reading)
+	tempParagraph.Text = fmt.Sprintf("Example Sensor: %.1f°C", reading)
+}
@@ -0,0 +1,184 @@
+// Copyright (c) 2024-2026 Carsen Klock under MIT License
+// exporter.go - Prometheus / OpenTelemetry metrics exporter for headless monitoring
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+)
+
+// exporterMode selects which wire format(s) mactop publishes metrics in.
+type exporterMode string
+
+const (
+	exporterNone       exporterMode = ""
+	exporterPrometheus exporterMode = "prometheus"
+	exporterOTLP       exporterMode = "otlp"
+	exporterBoth       exporterMode = "both"
+)
+
+var (
+	exporterFlag exporterMode
+	listenAddr   = ":9101"
+	otlpEndpoint string
+	otlpHeaders  map[string]string
+	otlpInterval = 10 * time.Second
+	exporterOnly bool
+	otlpGauges   struct {
+		cpu, mem, gpu, ane float64
+	}
+)
+
+// parseOTLPHeaders turns --otlp-headers' "Key1=Val1,Key2=Val2" syntax into a
+// map, the form otlpmetrichttp.WithHeaders wants. Malformed pairs (missing
+// "=") are skipped rather than rejected outright, so one typo doesn't block
+// startup.
+func parseOTLPHeaders(s string) map[string]string {
+	if s == "" {
+		return nil
+	}
+	headers := map[string]string{}
+	for _, pair := range strings.Split(s, ",") {
+		k, v, ok := strings.Cut(pair, "=")
+		if !ok {
+			continue
+		}
+		headers[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+	return headers
+}
+
+// startExporters brings up whichever exporters --exporter selected. It is
+// safe to call with exporterFlag == exporterNone, in which case it is a
+// no-op. registry must be non-nil whenever exporterFlag is exporterPrometheus
+// or exporterBoth; main() arranges that by building it (via newAPIRegistry)
+// ahead of this call.
+func startExporters(registry *prometheus.Registry) {
+	switch exporterFlag {
+	case exporterPrometheus:
+		startPrometheusExporter(listenAddr, registry)
+	case exporterOTLP:
+		startOTLPExporter(otlpEndpoint, otlpInterval)
+	case exporterBoth:
+		startPrometheusExporter(listenAddr, registry)
+		startOTLPExporter(otlpEndpoint, otlpInterval)
+	}
+}
+
+// startPrometheusExporter serves registry's /metrics endpoint on addr.
+// registry is the same *prometheus.Registry newAPIRegistry builds for
+// --prometheus/--api-socket, so --exporter=prometheus and --exporter=both
+// publish the identical mactop_* series rather than the default gatherer's
+// empty go_*/process_* output.
+func startPrometheusExporter(addr string, registry *prometheus.Registry) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+	go func() {
+		if err := http.ListenAndServe(addr, mux); err != nil {
+			stderrLogger.Printf("Failed to start metrics exporter on %s: %v\n", addr, err)
+		}
+	}()
+	stderrLogger.Printf("Prometheus exporter listening on %s/metrics\n", addr)
+}
+
+// otlpObservables mirrors the sampled state that the OTLP periodic reader
+// reads from on each collection tick, following OpenTelemetry semantic
+// conventions (system.*) with an apple.* namespace for vendor-specific gauges.
+type otlpObservables struct {
+	meter                               metric.Meter
+	cpuUtil, memUtil, gpuUtil, anePower metric.Float64ObservableGauge
+}
+
+var otlpState otlpObservables
+
+// startOTLPExporter wires up a periodic OTLP/HTTP metric reader that pushes
+// to endpoint every interval. Sampled values are read from the same globals
+// the TUI widgets use, so no second sampling loop is needed.
+func startOTLPExporter(endpoint string, interval time.Duration) {
+	if endpoint == "" {
+		stderrLogger.Printf("--otlp-endpoint is required for --exporter=otlp; skipping OTLP exporter\n")
+		return
+	}
+
+	ctx := context.Background()
+	opts := []otlpmetrichttp.Option{otlpmetrichttp.WithEndpoint(endpoint)}
+	if len(otlpHeaders) > 0 {
+		opts = append(opts, otlpmetrichttp.WithHeaders(otlpHeaders))
+	}
+	exp, err := otlpmetrichttp.New(ctx, opts...)
+	if err != nil {
+		stderrLogger.Printf("Failed to create OTLP exporter: %v\n", err)
+		return
+	}
+
+	hostname, _ := os.Hostname()
+	res, err := resource.New(ctx, resource.WithAttributes(
+		semconv.HostName(hostname),
+		attribute.String("device.model", chipModelName),
+		attribute.String("soc.name", chipModelName),
+	))
+	if err != nil {
+		stderrLogger.Printf("Failed to build OTLP resource, falling back to defaults: %v\n", err)
+		res = resource.Default()
+	}
+
+	reader := sdkmetric.NewPeriodicReader(exp, sdkmetric.WithInterval(interval))
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader), sdkmetric.WithResource(res))
+	meter := provider.Meter("github.com/context-labs/mactop")
+	otlpState.meter = meter
+
+	otlpState.cpuUtil, _ = meter.Float64ObservableGauge("system.cpu.utilization")
+	otlpState.memUtil, _ = meter.Float64ObservableGauge("system.memory.usage")
+	otlpState.gpuUtil, _ = meter.Float64ObservableGauge("apple.gpu.utilization")
+	otlpState.anePower, _ = meter.Float64ObservableGauge("apple.ane.power")
+
+	_, err = meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		o.ObserveFloat64(otlpState.cpuUtil, otlpGauges.cpu/100.0)
+		o.ObserveFloat64(otlpState.memUtil, otlpGauges.mem)
+		o.ObserveFloat64(otlpState.gpuUtil, otlpGauges.gpu/100.0)
+		o.ObserveFloat64(otlpState.anePower, otlpGauges.ane)
+		return nil
+	}, otlpState.cpuUtil, otlpState.memUtil, otlpState.gpuUtil, otlpState.anePower)
+	if err != nil {
+		stderrLogger.Printf("Failed to register OTLP callback: %v\n", err)
+		return
+	}
+
+	stderrLogger.Printf("OTLP metrics exporter pushing to %s every %s\n", endpoint, interval)
+}
+
+// recordOTLPCPUMem and recordOTLPGPU update the values read by the OTLP
+// observable callback. They're called independently from the CPU and GPU
+// update paths, which sample on separate channels.
+func recordOTLPCPUMem(cpuPercent, memFraction, anePower float64) {
+	otlpGauges.cpu = cpuPercent
+	otlpGauges.mem = memFraction
+	otlpGauges.ane = anePower
+}
+
+func recordOTLPGPU(gpuPercent float64) {
+	otlpGauges.gpu = gpuPercent
+}
+
+func parseExporterFlag(s string) (exporterMode, error) {
+	switch exporterMode(s) {
+	case exporterPrometheus, exporterOTLP, exporterBoth:
+		return exporterMode(s), nil
+	default:
+		return exporterNone, fmt.Errorf("unsupported --exporter value %q (want prometheus, otlp, or both)", s)
+	}
+}
@@ -3,13 +3,28 @@ package main
 import (
 	"encoding/json"
 	"fmt"
-	"net/http"
+	"io"
 	"os"
 	"time"
-
-	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
+// HeadlessOutput is one tick's sample in --exporter-only mode, rendered as
+// JSON, OpenMetrics text, or Influx line protocol depending on
+// --headless-format; see writeOpenMetricsSample/writeInfluxLineProtocol.
+type HeadlessOutput struct {
+	Timestamp    string                 `json:"timestamp"`
+	SocMetrics   SocMetrics             `json:"soc_metrics"`
+	Memory       MemoryMetrics          `json:"memory"`
+	NetDisk      NetDiskMetrics         `json:"net_disk"`
+	CPUUsage     float64                `json:"cpu_usage"`
+	GPUUsage     float64                `json:"gpu_usage"`
+	CoreUsages   []float64              `json:"core_usages"`
+	SystemInfo   map[string]interface{} `json:"system_info"`
+	ThermalState string                 `json:"thermal_state"`
+	CPUTemp      float32                `json:"cpu_temp"`
+	GPUTemp      float32                `json:"gpu_temp"`
+}
+
 func runHeadless(count int) {
 	if err := initSocMetrics(); err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to initialize metrics: %v\n", err)
@@ -17,37 +32,15 @@ func runHeadless(count int) {
 	}
 	defer cleanupSocMetrics()
 
-	if prometheusPort != "" {
-		go func() {
-			http.Handle("/metrics", promhttp.Handler())
-			if err := http.ListenAndServe(prometheusPort, nil); err != nil {
-				fmt.Fprintf(os.Stderr, "Prometheus server error: %v\n", err)
-			}
-		}()
-	}
-
 	ticker := time.NewTicker(time.Duration(updateInterval) * time.Millisecond)
 	defer ticker.Stop()
 
-	type HeadlessOutput struct {
-		Timestamp    string         `json:"timestamp"`
-		SocMetrics   SocMetrics     `json:"soc_metrics"`
-		Memory       MemoryMetrics  `json:"memory"`
-		NetDisk      NetDiskMetrics `json:"net_disk"`
-		CPUUsage     float64        `json:"cpu_usage"`
-		GPUUsage     float64        `json:"gpu_usage"`
-		CoreUsages   []float64      `json:"core_usages"`
-		SystemInfo   SystemInfo     `json:"system_info"`
-		ThermalState string         `json:"thermal_state"`
-		CPUTemp      float32        `json:"cpu_temp"`
-		GPUTemp      float32        `json:"gpu_temp"`
-	}
-
 	encoder := json.NewEncoder(os.Stdout)
+	openMetricsHeaderPrinted := false
 
 	GetCPUPercentages()
 
-	if count > 0 {
+	if count > 0 && headlessFormat == "json" {
 		fmt.Print("[")
 	}
 
@@ -67,7 +60,15 @@ func runHeadless(count int) {
 			cpuUsage = total / float64(len(percentages))
 		}
 
-		thermalStr, _ := getThermalStateString()
+		thermalStr, throttled := getThermalStateString()
+
+		recordSamples(
+			CPUMetrics{
+				CPUW: m.CPUPower, GPUW: m.GPUPower, ANEW: m.ANEPower, DRAMW: m.DRAMPower,
+				PackageW: m.TotalPower, Throttled: throttled, SocTemp: float64(m.SocTemp), Clusters: m.Clusters,
+			},
+			GPUMetrics{FreqMHz: int(m.GPUFreqMHz), Active: int(m.GPUActive), Temp: float64(m.SocTemp)},
+		)
 
 		output := HeadlessOutput{
 			Timestamp:    time.Now().Format(time.RFC3339),
@@ -79,22 +80,132 @@ func runHeadless(count int) {
 			CoreUsages:   percentages,
 			SystemInfo:   getSOCInfo(),
 			ThermalState: thermalStr,
-			CPUTemp:      m.CPUTemp,
-			GPUTemp:      m.GPUTemp,
+			CPUTemp:      m.SocTemp,
+			GPUTemp:      m.SocTemp,
 		}
 
-		if samplesCollected > 0 && count > 0 {
-			fmt.Print(",")
-		}
-
-		if err := encoder.Encode(output); err != nil {
-			fmt.Fprintf(os.Stderr, "Error encoding JSON: %v\n", err)
+		switch headlessFormat {
+		case "openmetrics":
+			writeOpenMetricsSample(os.Stdout, output, &openMetricsHeaderPrinted)
+		case "influx-lineproto":
+			writeInfluxLineProtocol(os.Stdout, output)
+		default:
+			if samplesCollected > 0 && count > 0 {
+				fmt.Print(",")
+			}
+			if err := encoder.Encode(output); err != nil {
+				fmt.Fprintf(os.Stderr, "Error encoding JSON: %v\n", err)
+			}
 		}
 
 		samplesCollected++
 		if count > 0 && samplesCollected >= count {
-			fmt.Println("]")
+			if headlessFormat == "json" {
+				fmt.Println("]")
+			}
+			return
+		}
+	}
+}
+
+// writeOpenMetricsSample renders one tick as OpenMetrics text-format metric
+// families covering the same fields the Prometheus /metrics endpoint
+// exposes. "# HELP"/"# TYPE" headers are written once, on the first sample,
+// then every tick just appends metric lines with a timestamp - this streams
+// well into an exec-based collector (telegraf's inputs.exec in prometheus
+// mode, or a tail | promtail pipeline), but unlike a single scrape response
+// it is not one self-contained OpenMetrics exposition document (that would
+// require a trailing "# EOF" and no repeated family headers across ticks).
+func writeOpenMetricsSample(w io.Writer, o HeadlessOutput, headerPrinted *bool) {
+	ts := float64(time.Now().UnixNano()) / 1e9
+
+	family := func(name, help, typ string) {
+		if *headerPrinted {
 			return
 		}
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+	}
+
+	family("mactop_cpu_usage_percent", "Current total CPU usage percentage", "gauge")
+	fmt.Fprintf(w, "mactop_cpu_usage_percent %f %f\n", o.CPUUsage, ts)
+
+	family("mactop_gpu_usage_percent", "Current GPU usage percentage", "gauge")
+	fmt.Fprintf(w, "mactop_gpu_usage_percent %f %f\n", o.GPUUsage, ts)
+
+	family("mactop_cpu_core_usage_percent", "Per-core CPU usage percentage", "gauge")
+	for i, pct := range o.CoreUsages {
+		fmt.Fprintf(w, "mactop_cpu_core_usage_percent{core=\"%d\"} %f %f\n", i, pct, ts)
+	}
+
+	family("mactop_memory_used_bytes", "Memory used in bytes", "gauge")
+	fmt.Fprintf(w, "mactop_memory_used_bytes %d %f\n", o.Memory.Used, ts)
+	family("mactop_memory_total_bytes", "Memory total in bytes", "gauge")
+	fmt.Fprintf(w, "mactop_memory_total_bytes %d %f\n", o.Memory.Total, ts)
+
+	family("mactop_network_kbytes_per_sec", "Network speed in KB/s", "gauge")
+	fmt.Fprintf(w, "mactop_network_kbytes_per_sec{direction=\"upload\"} %f %f\n", o.NetDisk.OutBytesPerSec, ts)
+	fmt.Fprintf(w, "mactop_network_kbytes_per_sec{direction=\"download\"} %f %f\n", o.NetDisk.InBytesPerSec, ts)
+
+	family("mactop_disk_iops", "Disk I/O operations per second", "gauge")
+	fmt.Fprintf(w, "mactop_disk_iops{operation=\"read\"} %f %f\n", o.NetDisk.ReadOpsPerSec, ts)
+	fmt.Fprintf(w, "mactop_disk_iops{operation=\"write\"} %f %f\n", o.NetDisk.WriteOpsPerSec, ts)
+
+	family("mactop_power_watts", "Power draw in watts by component", "gauge")
+	fmt.Fprintf(w, "mactop_power_watts{component=\"cpu\"} %f %f\n", o.SocMetrics.CPUPower, ts)
+	fmt.Fprintf(w, "mactop_power_watts{component=\"gpu\"} %f %f\n", o.SocMetrics.GPUPower, ts)
+	fmt.Fprintf(w, "mactop_power_watts{component=\"ane\"} %f %f\n", o.SocMetrics.ANEPower, ts)
+	fmt.Fprintf(w, "mactop_power_watts{component=\"dram\"} %f %f\n", o.SocMetrics.DRAMPower, ts)
+	fmt.Fprintf(w, "mactop_power_watts{component=\"total\"} %f %f\n", o.SocMetrics.TotalPower, ts)
+
+	family("mactop_cpu_temp_celsius", "CPU die temperature in Celsius", "gauge")
+	fmt.Fprintf(w, "mactop_cpu_temp_celsius %f %f\n", o.CPUTemp, ts)
+	family("mactop_gpu_temp_celsius", "GPU die temperature in Celsius", "gauge")
+	fmt.Fprintf(w, "mactop_gpu_temp_celsius %f %f\n", o.GPUTemp, ts)
+
+	family("mactop_thermal_state", "macOS thermal state: 0=nominal, 1=fair, 2=serious, 3=critical", "gauge")
+	fmt.Fprintf(w, "mactop_thermal_state %d %f\n", thermalStateNameToInt(o.ThermalState), ts)
+
+	*headerPrinted = true
+}
+
+// writeInfluxLineProtocol renders one tick as Influx line protocol, one line
+// per measurement with tags for component/core index, matching the shape a
+// Telegraf gauge input expects from `inputs.exec`.
+func writeInfluxLineProtocol(w io.Writer, o HeadlessOutput) {
+	tsNanos := time.Now().UnixNano()
+
+	fmt.Fprintf(w, "mactop_cpu usage_percent=%f %d\n", o.CPUUsage, tsNanos)
+	fmt.Fprintf(w, "mactop_gpu usage_percent=%f %d\n", o.GPUUsage, tsNanos)
+	for i, pct := range o.CoreUsages {
+		fmt.Fprintf(w, "mactop_cpu_core,core=%d usage_percent=%f %d\n", i, pct, tsNanos)
+	}
+	fmt.Fprintf(w, "mactop_memory used_bytes=%d,total_bytes=%d %d\n", o.Memory.Used, o.Memory.Total, tsNanos)
+	fmt.Fprintf(w, "mactop_network,direction=upload kbytes_per_sec=%f %d\n", o.NetDisk.OutBytesPerSec, tsNanos)
+	fmt.Fprintf(w, "mactop_network,direction=download kbytes_per_sec=%f %d\n", o.NetDisk.InBytesPerSec, tsNanos)
+	fmt.Fprintf(w, "mactop_disk,operation=read iops=%f %d\n", o.NetDisk.ReadOpsPerSec, tsNanos)
+	fmt.Fprintf(w, "mactop_disk,operation=write iops=%f %d\n", o.NetDisk.WriteOpsPerSec, tsNanos)
+	fmt.Fprintf(w, "mactop_power,component=cpu watts=%f %d\n", o.SocMetrics.CPUPower, tsNanos)
+	fmt.Fprintf(w, "mactop_power,component=gpu watts=%f %d\n", o.SocMetrics.GPUPower, tsNanos)
+	fmt.Fprintf(w, "mactop_power,component=ane watts=%f %d\n", o.SocMetrics.ANEPower, tsNanos)
+	fmt.Fprintf(w, "mactop_power,component=dram watts=%f %d\n", o.SocMetrics.DRAMPower, tsNanos)
+	fmt.Fprintf(w, "mactop_power,component=total watts=%f %d\n", o.SocMetrics.TotalPower, tsNanos)
+	fmt.Fprintf(w, "mactop_temp,component=cpu celsius=%f %d\n", o.CPUTemp, tsNanos)
+	fmt.Fprintf(w, "mactop_temp,component=gpu celsius=%f %d\n", o.GPUTemp, tsNanos)
+	fmt.Fprintf(w, "mactop_thermal state=\"%s\",state_num=%di %d\n", o.ThermalState, thermalStateNameToInt(o.ThermalState), tsNanos)
+}
+
+// thermalStateNameToInt maps getThermalStateString's result back to the
+// 0-3 numeric scale mactop_thermal_state/mactop_thermal_pressure already use
+// elsewhere (see updateCPUUI).
+func thermalStateNameToInt(name string) int {
+	switch name {
+	case "Fair":
+		return 1
+	case "Serious":
+		return 2
+	case "Critical":
+		return 3
+	default:
+		return 0
 	}
 }
@@ -0,0 +1,204 @@
+// Copyright (c) 2024-2026 Carsen Klock under MIT License
+// history.go - an always-on rolling store of sampled metrics: a fixed-size
+// in-memory ring buffer (historyRing) that serves recent spikes instantly
+// even when --history wasn't given, backed by an on-disk JSON-lines append
+// log under ~/.mactop/history/ when it was, so a spike can still be found
+// after a restart. This is distinct from --record's explicit .mtop session
+// captures (recorder.go), which snapshot full process/memory state for
+// frame-by-frame replay rather than a lightweight always-on trend log.
+// External dashboards pull it via GET /history.json?since=<RFC3339>.
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	historyFlag     time.Duration // 0 = on-disk persistence disabled; ring buffer still runs
+	historyDir      string
+	historyFile     *os.File
+	historyEncoder  *json.Encoder
+	historyFileDate string
+	historyMu       sync.Mutex
+
+	// historyRing holds the most recent ringCapacity points in memory so
+	// /history.json and a future sparkline "zoom" have something to serve
+	// even when --history was never passed.
+	historyRing     []HistoryPoint
+	ringCapacity    = 3600 // ~1h at a 1s tick
+	lastDiskNetRate diskNetRate
+)
+
+// diskNetRate is the most recent disk/network throughput sample, set by
+// updateNetDiskUI and folded into the next recordHistoryPoint call from
+// updateCPUUI, mirroring how lastPackageWatts bridges the two update paths.
+type diskNetRate struct {
+	DiskReadKBs  float64
+	DiskWriteKBs float64
+	NetInKBs     float64
+	NetOutKBs    float64
+}
+
+// HistoryPoint is one line of a history file (and one ring buffer entry).
+type HistoryPoint struct {
+	Timestamp    time.Time `json:"timestamp"`
+	CPUPercent   float64   `json:"cpu_percent"`
+	CPUWatts     float64   `json:"cpu_watts"`
+	GPUWatts     float64   `json:"gpu_watts"`
+	ANEWatts     float64   `json:"ane_watts"`
+	DRAMWatts    float64   `json:"dram_watts"`
+	PackageW     float64   `json:"package_watts"`
+	SocTemp      float64   `json:"soc_temp"`
+	MemUsedGB    float64   `json:"mem_used_gb"`
+	DiskReadKBs  float64   `json:"disk_read_kbs"`
+	DiskWriteKBs float64   `json:"disk_write_kbs"`
+	NetInKBs     float64   `json:"net_in_kbs"`
+	NetOutKBs    float64   `json:"net_out_kbs"`
+}
+
+// startHistoryStore prepares ~/.mactop/history for writing and prunes any
+// daily file whose date is older than retention. Does nothing if historyFlag
+// is 0 (the --history flag wasn't given).
+func startHistoryStore(retention time.Duration) {
+	if retention <= 0 {
+		return
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return
+	}
+	historyDir = filepath.Join(homeDir, ".mactop", "history")
+	if err := os.MkdirAll(historyDir, 0755); err != nil {
+		stderrLogger.Printf("Failed to create history dir: %v\n", err)
+		return
+	}
+	pruneHistory(retention)
+}
+
+func historyFilePath(t time.Time) string {
+	return filepath.Join(historyDir, t.Format("2006-01-02")+".jsonl")
+}
+
+// pruneHistory removes daily history files whose date is entirely outside
+// the retention window.
+func pruneHistory(retention time.Duration) {
+	entries, err := os.ReadDir(historyDir)
+	if err != nil {
+		return
+	}
+	cutoff := time.Now().Add(-retention)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".jsonl") {
+			continue
+		}
+		day, err := time.Parse("2006-01-02", strings.TrimSuffix(name, ".jsonl"))
+		if err != nil {
+			continue
+		}
+		if day.AddDate(0, 0, 1).Before(cutoff) {
+			os.Remove(filepath.Join(historyDir, name))
+		}
+	}
+}
+
+// recordHistoryPoint appends one sample to the in-memory ring buffer, and
+// also to today's on-disk history file (rolling over to a new file, and
+// pruning old ones, at midnight) if --history enabled startHistoryStore.
+func recordHistoryPoint(p HistoryPoint) {
+	historyMu.Lock()
+	historyRing = append(historyRing, p)
+	if len(historyRing) > ringCapacity {
+		historyRing = historyRing[len(historyRing)-ringCapacity:]
+	}
+	historyMu.Unlock()
+
+	if historyDir == "" {
+		return
+	}
+	historyMu.Lock()
+	defer historyMu.Unlock()
+
+	today := p.Timestamp.Format("2006-01-02")
+	if today != historyFileDate {
+		if historyFile != nil {
+			historyFile.Close()
+		}
+		f, err := os.OpenFile(historyFilePath(p.Timestamp), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			stderrLogger.Printf("Failed to open history file: %v\n", err)
+			return
+		}
+		historyFile = f
+		historyEncoder = json.NewEncoder(f)
+		historyFileDate = today
+		pruneHistory(historyFlag)
+	}
+	if err := historyEncoder.Encode(p); err != nil {
+		stderrLogger.Printf("Failed to append history point: %v\n", err)
+	}
+}
+
+// historyHandler serves GET /history.json?since=<RFC3339>. If --history
+// persisted to disk, it scans every daily file (they're small and few, so
+// no index is worth the complexity); otherwise it falls back to whatever
+// the in-memory ring buffer still holds, so the endpoint works even
+// without --history, bounded to the ring's capacity. since defaults to the
+// start of the retention window, or to "ring start" when there's no
+// retention window to speak of.
+func historyHandler(w http.ResponseWriter, r *http.Request) {
+	since := time.Now().Add(-historyFlag)
+	if s := r.URL.Query().Get("since"); s != "" {
+		if t, err := time.Parse(time.RFC3339, s); err == nil {
+			since = t
+		}
+	}
+
+	var points []HistoryPoint
+	if historyDir == "" {
+		historyMu.Lock()
+		for _, p := range historyRing {
+			if !p.Timestamp.Before(since) {
+				points = append(points, p)
+			}
+		}
+		historyMu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(points)
+		return
+	}
+
+	entries, err := os.ReadDir(historyDir)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to read history: %v", err), http.StatusInternalServerError)
+		return
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".jsonl") {
+			continue
+		}
+		f, err := os.Open(filepath.Join(historyDir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			var p HistoryPoint
+			if json.Unmarshal(scanner.Bytes(), &p) == nil && !p.Timestamp.Before(since) {
+				points = append(points, p)
+			}
+		}
+		f.Close()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(points)
+}
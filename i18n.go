@@ -0,0 +1,137 @@
+// Copyright (c) 2024-2026 Carsen Klock under MIT License
+// i18n.go - localization of the strings mactop prints to the terminal.
+// Translations are flat key = "value" TOML files (the same minimal subset
+// alerts.go's thresholds parser uses) embedded from translations/ so the
+// binary works standalone, with en_US as the ground truth every other
+// locale falls back to for any key it doesn't list. Selected via --lang or
+// $LANG, falling back to en_US if neither names a known locale.
+package main
+
+import (
+	"bufio"
+	"embed"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+//go:embed translations/*.toml
+var embeddedTranslations embed.FS
+
+const defaultLocale = "en_US"
+
+var (
+	langFlag        string
+	dumpStringsFlag bool
+	activeLocale    = defaultLocale
+	activeStrings   map[string]string
+	fallbackStrings map[string]string
+)
+
+// resolveLocale picks --lang if given, else $LANG (trimmed to its
+// languageCOUNTRY prefix, e.g. "de_DE.UTF-8" -> "de_DE"), else defaultLocale.
+func resolveLocale() string {
+	if langFlag != "" {
+		return langFlag
+	}
+	if env := os.Getenv("LANG"); env != "" {
+		locale := strings.SplitN(env, ".", 2)[0]
+		if locale != "" {
+			return locale
+		}
+	}
+	return defaultLocale
+}
+
+// loadTranslations reads the embedded defaultLocale strings (always needed,
+// as the fallback chain's root) plus the resolved locale's strings, if it's
+// not defaultLocale itself and a matching file exists.
+func loadTranslations() {
+	fallbackStrings = mustLoadEmbedded(defaultLocale)
+
+	activeLocale = resolveLocale()
+	if activeLocale == defaultLocale {
+		activeStrings = fallbackStrings
+		return
+	}
+	strs, err := loadEmbedded(activeLocale)
+	if err != nil {
+		stderrLogger.Printf("No translation for locale %q, falling back to %s: %v\n", activeLocale, defaultLocale, err)
+		activeLocale = defaultLocale
+		activeStrings = fallbackStrings
+		return
+	}
+	activeStrings = strs
+}
+
+func loadEmbedded(locale string) (map[string]string, error) {
+	data, err := embeddedTranslations.ReadFile("translations/" + locale + ".toml")
+	if err != nil {
+		return nil, err
+	}
+	return parseFlatTOML(string(data)), nil
+}
+
+func mustLoadEmbedded(locale string) map[string]string {
+	strs, err := loadEmbedded(locale)
+	if err != nil {
+		stderrLogger.Printf("Missing embedded translation %q: %v\n", locale, err)
+		return map[string]string{}
+	}
+	return strs
+}
+
+// parseFlatTOML parses `key = "value"` lines, ignoring blank lines and "#"
+// comments. No sections, nesting, or inline collections - translation files
+// don't need them.
+func parseFlatTOML(content string) map[string]string {
+	strs := map[string]string{}
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		idx := strings.Index(line, "=")
+		if idx == -1 {
+			continue
+		}
+		key := strings.TrimSpace(line[:idx])
+		value := strings.TrimSpace(strings.Trim(strings.TrimSpace(line[idx+1:]), `"`))
+		strs[key] = value
+	}
+	return strs
+}
+
+// tr looks up key in the active locale, falling back to en_US, then to key
+// itself so a missing translation degrades to a readable (if English)
+// string instead of blank text. args are applied with fmt.Sprintf if given.
+func tr(key string, args ...interface{}) string {
+	value, ok := activeStrings[key]
+	if !ok {
+		value, ok = fallbackStrings[key]
+	}
+	if !ok {
+		value = key
+	}
+	if len(args) == 0 {
+		return value
+	}
+	return fmt.Sprintf(value, args...)
+}
+
+// runDumpStrings implements `mactop --dump-strings`: prints every en_US key
+// as a translator-ready TOML template.
+func runDumpStrings() {
+	keys := make([]string, 0, len(fallbackStrings))
+	for k := range fallbackStrings {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	fmt.Println("# Translation template generated by `mactop --dump-strings`.")
+	fmt.Println("# Copy to translations/<locale>.toml and translate each value.")
+	for _, k := range keys {
+		fmt.Printf("%s = %q\n", k, fallbackStrings[k])
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright (c) 2024-2026 Carsen Klock under MIT License
+// Package render provides a Unicode braille sub-cell canvas for drawing
+// higher-resolution sparklines and graphs than termui's block characters
+// allow: each terminal cell holds a 2x4 grid of braille dots, giving 8x the
+// effective resolution per cell.
+package render
+
+// BrailleCanvas is a bit grid of width*2 x height*4 dots, addressed in dot
+// coordinates, that rasterizes down to width x height braille runes.
+type BrailleCanvas struct {
+	width, height int // in terminal cells
+	dots          [][]bool
+}
+
+// brailleDotBit maps a dot's position within its 2x4 cell to the bit it sets
+// in the 0x2800-based braille codepoint, per the Unicode braille pattern layout.
+var brailleDotBit = [4][2]uint8{
+	{0x01, 0x08},
+	{0x02, 0x10},
+	{0x04, 0x20},
+	{0x40, 0x80},
+}
+
+// NewBrailleCanvas creates a canvas covering width x height terminal cells,
+// i.e. width*2 x height*4 addressable dots.
+func NewBrailleCanvas(width, height int) *BrailleCanvas {
+	if width < 0 {
+		width = 0
+	}
+	if height < 0 {
+		height = 0
+	}
+	dots := make([][]bool, height*4)
+	for i := range dots {
+		dots[i] = make([]bool, width*2)
+	}
+	return &BrailleCanvas{width: width, height: height, dots: dots}
+}
+
+// Set turns on the dot at dot-coordinate (x, y).
+func (c *BrailleCanvas) Set(x, y int) {
+	if y < 0 || y >= len(c.dots) || x < 0 || x >= c.width*2 {
+		return
+	}
+	c.dots[y][x] = true
+}
+
+// Unset turns off the dot at dot-coordinate (x, y).
+func (c *BrailleCanvas) Unset(x, y int) {
+	if y < 0 || y >= len(c.dots) || x < 0 || x >= c.width*2 {
+		return
+	}
+	c.dots[y][x] = false
+}
+
+// Width and Height report the canvas size in terminal cells.
+func (c *BrailleCanvas) Width() int  { return c.width }
+func (c *BrailleCanvas) Height() int { return c.height }
+
+// Render rasterizes the dot grid down to one braille rune per terminal cell.
+func (c *BrailleCanvas) Render() [][]rune {
+	cells := make([][]rune, c.height)
+	for cy := 0; cy < c.height; cy++ {
+		row := make([]rune, c.width)
+		for cx := 0; cx < c.width; cx++ {
+			var codepoint rune = 0x2800
+			for dy := 0; dy < 4; dy++ {
+				for dx := 0; dx < 2; dx++ {
+					if c.dots[cy*4+dy][cx*2+dx] {
+						codepoint |= rune(brailleDotBit[dy][dx])
+					}
+				}
+			}
+			row[cx] = codepoint
+		}
+		cells[cy] = row
+	}
+	return cells
+}
+
+// PlotLine draws a simple line series scaled into the canvas's dot space,
+// one dot-column per sample, values clamped to [0, max].
+func (c *BrailleCanvas) PlotLine(values []float64, max float64) {
+	dotHeight := c.height * 4
+	dotWidth := c.width * 2
+	if dotHeight == 0 || dotWidth == 0 || len(values) == 0 || max <= 0 {
+		return
+	}
+	start := 0
+	if len(values) > dotWidth {
+		start = len(values) - dotWidth
+	}
+	for i, v := range values[start:] {
+		if v < 0 {
+			v = 0
+		}
+		if v > max {
+			v = max
+		}
+		filled := int((v / max) * float64(dotHeight))
+		for dy := dotHeight - filled; dy < dotHeight; dy++ {
+			c.Set(i, dy)
+		}
+	}
+}
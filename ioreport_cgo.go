@@ -1,5 +1,13 @@
+//go:build cgo && darwin
+
 // Copyright (c) 2024-2026 Carsen Klock under MIT License
-// ioreport.go - Go wrappers for IOReport power/thermal metrics
+// ioreport_cgo.go - Go wrappers for IOReport power/thermal metrics, via cgo.
+// This is the default Darwin build: it needs an Xcode toolchain (cgo CFLAGS/
+// LDFLAGS below), so CGO_ENABLED=0 cross-compiles (e.g. from Linux/Windows
+// CI) fall through to ioreport_purego.go instead, which resolves the same
+// symbols at runtime with purego. Keep SocMetrics and the four exported
+// functions identical across both files - main.go doesn't know which one it
+// linked against.
 package main
 
 /*
@@ -60,19 +68,6 @@ int getThermalState();
 */
 import "C"
 
-type SocMetrics struct {
-	CPUPower     float64 `json:"cpu_power"`
-	GPUPower     float64 `json:"gpu_power"`
-	ANEPower     float64 `json:"ane_power"`
-	DRAMPower    float64 `json:"dram_power"`
-	GPUSRAMPower float64 `json:"gpu_sram_power"`
-	SystemPower  float64 `json:"system_power"`
-	TotalPower   float64 `json:"total_power"`
-	GPUFreqMHz   int32   `json:"gpu_freq_mhz"`
-	GPUActive    float64 `json:"-"`
-	SocTemp      float32 `json:"soc_temp"`
-}
-
 func initSocMetrics() error {
 	if ret := C.initIOReport(); ret != 0 {
 		return nil
@@ -80,6 +75,12 @@ func initSocMetrics() error {
 	return nil
 }
 
+// sampleSocMetrics has no per-cluster residency breakdown on this path: it
+// hands off entirely to samplePowerMetrics' opaque C implementation, which
+// would need its own PowerMetrics struct extended with cluster state
+// arrays to expose one, mirroring what ioreport_purego.go's sampleClusters
+// does at the Go level against the same CPU Stats/GPU Stats IOReport
+// groups. Until that C-side work lands, Clusters is always empty here.
 func sampleSocMetrics(durationMs int) SocMetrics {
 	pm := C.samplePowerMetrics(C.int(durationMs))
 	return SocMetrics{
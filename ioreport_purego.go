@@ -0,0 +1,440 @@
+//go:build !cgo && darwin
+
+// Copyright (c) 2024-2026 Carsen Klock under MIT License
+// ioreport_purego.go - Go wrappers for IOReport power/thermal metrics, via
+// github.com/ebitengine/purego instead of cgo (the same approach gopsutil's
+// Darwin collectors and nezha-agent's GPU/SMC readers use). This lets
+// CGO_ENABLED=0 builds cross-compile mactop from Linux/Windows CI without an
+// Xcode toolchain. See ioreport_cgo.go for the cgo-path sibling; both must
+// expose the identical SocMetrics/initSocMetrics/sampleSocMetrics/
+// cleanupSocMetrics/getSocThermalState surface so main.go is build-path
+// agnostic.
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+	"unsafe"
+
+	"github.com/ebitengine/purego"
+)
+
+// cfRef mirrors a CoreFoundation object pointer (CFTypeRef/CFStringRef/
+// CFDictionaryRef/CFArrayRef/...); purego calls these across the board as
+// uintptr since it has no CF-aware types of its own.
+type cfRef = uintptr
+
+var (
+	coreFoundation uintptr
+	ioKit          uintptr
+
+	cfStringCreateWithCString func(alloc cfRef, cstr string, encoding uint32) cfRef
+	cfDictionaryGetValue      func(dict cfRef, key cfRef) cfRef
+	cfArrayGetCount           func(arr cfRef) int64
+	cfArrayGetValueAtIndex    func(arr cfRef, idx int64) cfRef
+	cfNumberGetValue          func(num cfRef, theType int32, out unsafe.Pointer) bool
+	cfRelease                 func(obj cfRef)
+	cfDictionaryCreateMutable func(alloc cfRef, capacity int64, keyCallBacks, valueCallBacks uintptr) cfRef
+
+	ioReportCopyChannelsInGroup   func(group, subgroup cfRef, a, b, c uint64) cfRef
+	ioReportMergeChannels         func(a, b cfRef, unused cfRef)
+	ioReportCreateSubscription    func(a uintptr, channels cfRef, out *cfRef, d uint64, e cfRef) uintptr
+	ioReportCreateSamples         func(sub uintptr, channels cfRef, unused cfRef) cfRef
+	ioReportCreateSamplesDelta    func(a, b cfRef, unused cfRef) cfRef
+	ioReportSimpleGetIntegerValue func(item cfRef, idx int32) int64
+	ioReportChannelGetGroup       func(item cfRef) cfRef
+	ioReportChannelGetChannelName func(item cfRef) cfRef
+	ioReportStateGetCount         func(item cfRef) int32
+	ioReportStateGetNameForIndex  func(item cfRef, idx int32) cfRef
+	ioReportStateGetResidency     func(item cfRef, idx int32) int64
+
+	ioHIDEventSystemClientCreate       func(allocator cfRef) uintptr
+	ioHIDEventSystemClientSetMatching  func(client uintptr, matching cfRef) int32
+	ioHIDEventSystemClientCopyServices func(client uintptr) cfRef
+	ioHIDServiceClientCopyProperty     func(service uintptr, key cfRef) cfRef
+	ioHIDServiceClientCopyEvent        func(service uintptr, eventType int64, options int32, timeout int64) uintptr
+	ioHIDEventGetFloatValue            func(event uintptr, field int64) float64
+
+	socReportSubscription uintptr
+	socChannelDict        cfRef
+	lastSample            cfRef
+	hidClient             uintptr
+	hidInit               bool
+
+	// clusterReportSubscription/clusterChannelDict back the "CPU Stats" +
+	// "GPU Stats" groups' residency channels (ECPU/PCPU0/PCPU1/GPUPH/...),
+	// separate from socReportSubscription's "Energy Model" group above since
+	// they're different IOReport groups.
+	clusterReportSubscription uintptr
+	clusterChannelDict        cfRef
+)
+
+const kCFStringEncodingUTF8 = 0x08000100
+
+// initSocMetrics resolves every CF/IOKit/IOReport symbol this package needs
+// via dlopen + purego.RegisterLibFunc, then opens the power-metrics
+// subscription the same way ioreport_cgo.go's initIOReport does: copy the
+// "Energy Model" channels, build a subscription, and keep it open for
+// sampleSocMetrics to read off of each tick.
+func initSocMetrics() error {
+	var err error
+	coreFoundation, err = purego.Dlopen("/System/Library/Frameworks/CoreFoundation.framework/CoreFoundation", purego.RTLD_NOW|purego.RTLD_GLOBAL)
+	if err != nil {
+		return fmt.Errorf("ioreport_purego: dlopen CoreFoundation: %w", err)
+	}
+	ioKit, err = purego.Dlopen("/System/Library/Frameworks/IOKit.framework/IOKit", purego.RTLD_NOW|purego.RTLD_GLOBAL)
+	if err != nil {
+		return fmt.Errorf("ioreport_purego: dlopen IOKit: %w", err)
+	}
+
+	purego.RegisterLibFunc(&cfStringCreateWithCString, coreFoundation, "CFStringCreateWithCString")
+	purego.RegisterLibFunc(&cfDictionaryGetValue, coreFoundation, "CFDictionaryGetValue")
+	purego.RegisterLibFunc(&cfArrayGetCount, coreFoundation, "CFArrayGetCount")
+	purego.RegisterLibFunc(&cfArrayGetValueAtIndex, coreFoundation, "CFArrayGetValueAtIndex")
+	purego.RegisterLibFunc(&cfNumberGetValue, coreFoundation, "CFNumberGetValue")
+	purego.RegisterLibFunc(&cfRelease, coreFoundation, "CFRelease")
+	purego.RegisterLibFunc(&cfDictionaryCreateMutable, coreFoundation, "CFDictionaryCreateMutable")
+
+	// IOReport itself ships inside IOKit.framework on modern macOS (there is
+	// no separate IOReport.framework to dlopen), which is why the cgo
+	// sibling links it with `-framework IOKit -lIOReport` rather than a
+	// private-framework path.
+	purego.RegisterLibFunc(&ioReportCopyChannelsInGroup, ioKit, "IOReportCopyChannelsInGroup")
+	purego.RegisterLibFunc(&ioReportMergeChannels, ioKit, "IOReportMergeChannels")
+	purego.RegisterLibFunc(&ioReportCreateSubscription, ioKit, "IOReportCreateSubscription")
+	purego.RegisterLibFunc(&ioReportCreateSamples, ioKit, "IOReportCreateSamples")
+	purego.RegisterLibFunc(&ioReportCreateSamplesDelta, ioKit, "IOReportCreateSamplesDelta")
+	purego.RegisterLibFunc(&ioReportSimpleGetIntegerValue, ioKit, "IOReportSimpleGetIntegerValue")
+	purego.RegisterLibFunc(&ioReportChannelGetGroup, ioKit, "IOReportChannelGetGroup")
+	purego.RegisterLibFunc(&ioReportChannelGetChannelName, ioKit, "IOReportChannelGetChannelName")
+	purego.RegisterLibFunc(&ioReportStateGetCount, ioKit, "IOReportStateGetCount")
+	purego.RegisterLibFunc(&ioReportStateGetNameForIndex, ioKit, "IOReportStateGetNameForIndex")
+	purego.RegisterLibFunc(&ioReportStateGetResidency, ioKit, "IOReportStateGetResidency")
+
+	purego.RegisterLibFunc(&ioHIDEventSystemClientCreate, ioKit, "IOHIDEventSystemClientCreate")
+	purego.RegisterLibFunc(&ioHIDEventSystemClientSetMatching, ioKit, "IOHIDEventSystemClientSetMatching")
+	purego.RegisterLibFunc(&ioHIDEventSystemClientCopyServices, ioKit, "IOHIDEventSystemClientCopyServices")
+	purego.RegisterLibFunc(&ioHIDServiceClientCopyProperty, ioKit, "IOHIDServiceClientCopyProperty")
+	purego.RegisterLibFunc(&ioHIDServiceClientCopyEvent, ioKit, "IOHIDServiceClientCopyEvent")
+	purego.RegisterLibFunc(&ioHIDEventGetFloatValue, ioKit, "IOHIDEventGetFloatValue")
+
+	energyModel := cfStringCreateWithCString(0, "Energy Model", kCFStringEncodingUTF8)
+	defer cfRelease(energyModel)
+	channels := ioReportCopyChannelsInGroup(energyModel, 0, 0, 0, 0)
+	if channels == 0 {
+		return fmt.Errorf("ioreport_purego: IOReportCopyChannelsInGroup returned nil for Energy Model")
+	}
+	socChannelDict = channels
+
+	var subOut cfRef
+	sub := ioReportCreateSubscription(0, channels, &subOut, 0, 0)
+	if sub == 0 {
+		return fmt.Errorf("ioreport_purego: IOReportCreateSubscription failed")
+	}
+	socReportSubscription = sub
+
+	if err := initClusterSubscription(); err != nil {
+		// Non-fatal: mactop still runs with the collapsed CPU/GPU power
+		// totals from the Energy Model subscription above, just without
+		// the per-cluster residency breakdown.
+		clusterReportSubscription = 0
+	}
+
+	hidInit = initThermalHID()
+	return nil
+}
+
+// initClusterSubscription opens a second IOReport subscription against the
+// "CPU Stats" and "GPU Stats" groups, whose channels are per-DVFS-cluster
+// (ECPU, PCPU0, PCPU1, GPUPH, ...) residency state counters rather than the
+// Energy Model group's already-collapsed per-domain energy totals.
+func initClusterSubscription() error {
+	cpuStats := cfStringCreateWithCString(0, "CPU Stats", kCFStringEncodingUTF8)
+	defer cfRelease(cpuStats)
+	gpuStats := cfStringCreateWithCString(0, "GPU Stats", kCFStringEncodingUTF8)
+	defer cfRelease(gpuStats)
+
+	cpuChannels := ioReportCopyChannelsInGroup(cpuStats, 0, 0, 0, 0)
+	gpuChannels := ioReportCopyChannelsInGroup(gpuStats, 0, 0, 0, 0)
+	if cpuChannels == 0 && gpuChannels == 0 {
+		return fmt.Errorf("ioreport_purego: no CPU Stats or GPU Stats channels available")
+	}
+	if cpuChannels != 0 && gpuChannels != 0 {
+		ioReportMergeChannels(cpuChannels, gpuChannels, 0)
+		cfRelease(gpuChannels)
+	} else if gpuChannels != 0 {
+		cpuChannels = gpuChannels
+	}
+	clusterChannelDict = cpuChannels
+
+	var subOut cfRef
+	sub := ioReportCreateSubscription(0, cpuChannels, &subOut, 0, 0)
+	if sub == 0 {
+		return fmt.Errorf("ioreport_purego: IOReportCreateSubscription failed for cluster channels")
+	}
+	clusterReportSubscription = sub
+	return nil
+}
+
+// initThermalHID opens the IOHIDEventSystem client used by
+// getSocThermalState for the SoC temperature sensor, mirroring the cgo
+// path's getThermalState. Failure here is non-fatal: the thermal widget
+// just reads 0 (Nominal) if it can't find the sensor.
+func initThermalHID() bool {
+	client := ioHIDEventSystemClientCreate(0)
+	if client == 0 {
+		return false
+	}
+	hidClient = client
+	return true
+}
+
+// sampleSocMetrics takes one IOReport delta sample over durationMs and
+// extracts the per-domain energy-model channels the cgo path also reads:
+// CPU/GPU/ANE/DRAM/GPU SRAM energy, converted from the delta's
+// millijoules-over-duration into watts.
+func sampleSocMetrics(durationMs int) SocMetrics {
+	var metrics SocMetrics
+	if socReportSubscription == 0 {
+		return metrics
+	}
+
+	first := ioReportCreateSamples(socReportSubscription, socChannelDict, 0)
+	if first == 0 {
+		return metrics
+	}
+	time.Sleep(time.Duration(durationMs) * time.Millisecond)
+	second := ioReportCreateSamples(socReportSubscription, socChannelDict, 0)
+	if second == 0 {
+		cfRelease(first)
+		return metrics
+	}
+	delta := ioReportCreateSamplesDelta(first, second, 0)
+	cfRelease(first)
+	cfRelease(second)
+	if delta == 0 {
+		return metrics
+	}
+	defer cfRelease(delta)
+
+	seconds := float64(durationMs) / 1000
+	count := cfArrayGetCount(delta)
+	for i := int64(0); i < count; i++ {
+		item := cfArrayGetValueAtIndex(delta, i)
+		name := cfStringToGo(ioReportChannelGetChannelName(item))
+		millijoules := float64(ioReportSimpleGetIntegerValue(item, 0))
+		watts := 0.0
+		if seconds > 0 {
+			watts = millijoules / 1000 / seconds
+		}
+		switch name {
+		case "CPU Energy":
+			metrics.CPUPower = watts
+		case "GPU Energy":
+			metrics.GPUPower = watts
+		case "ANE Energy":
+			metrics.ANEPower = watts
+		case "DRAM Energy":
+			metrics.DRAMPower = watts
+		case "GPU SRAM Energy":
+			metrics.GPUSRAMPower = watts
+		case "System Energy":
+			metrics.SystemPower = watts
+		}
+	}
+	metrics.TotalPower = metrics.CPUPower + metrics.GPUPower + metrics.ANEPower + metrics.DRAMPower + metrics.GPUSRAMPower
+	metrics.SocTemp = readSocTemp()
+	metrics.Clusters = sampleClusters(durationMs)
+	apportionClusterPower(metrics.Clusters, metrics.CPUPower, metrics.GPUPower)
+	return metrics
+}
+
+// apportionClusterPower splits the Energy Model group's collapsed
+// cpuPower/gpuPower across clusters proportionally to each cluster's
+// active-residency share, since the CPU Stats/GPU Stats group reports
+// residency, not energy, per cluster. GPU-named clusters (by convention,
+// "GPUPH"-style names) draw from gpuPower; everything else draws from
+// cpuPower.
+func apportionClusterPower(clusters []ClusterMetrics, cpuPower, gpuPower float64) {
+	var cpuActiveTotal, gpuActiveTotal float64
+	for i := range clusters {
+		if isGPUCluster(clusters[i].Name) {
+			gpuActiveTotal += clusters[i].ActiveResidency
+		} else {
+			cpuActiveTotal += clusters[i].ActiveResidency
+		}
+	}
+	for i := range clusters {
+		if isGPUCluster(clusters[i].Name) {
+			if gpuActiveTotal > 0 {
+				clusters[i].PowerW = gpuPower * clusters[i].ActiveResidency / gpuActiveTotal
+			}
+		} else if cpuActiveTotal > 0 {
+			clusters[i].PowerW = cpuPower * clusters[i].ActiveResidency / cpuActiveTotal
+		}
+	}
+}
+
+func isGPUCluster(name string) bool {
+	return len(name) >= 3 && name[:3] == "GPU"
+}
+
+// sampleClusters brackets its own IOReportCreateSamples pair around the
+// cluster (CPU Stats/GPU Stats) subscription, the same delta-of-two-samples
+// shape as sampleSocMetrics above, then turns each channel's state bins
+// into a ClusterMetrics: active/idle residency fractions, a per-cluster
+// effective frequency (Σ freq*residency / Σ residency), and a power
+// estimate split from the Energy Model's collapsed CPUPower/GPUPower
+// proportionally by each cluster's active residency share, since IOReport's
+// per-cluster channels report residency, not energy, directly.
+func sampleClusters(durationMs int) []ClusterMetrics {
+	if clusterReportSubscription == 0 {
+		return nil
+	}
+	first := ioReportCreateSamples(clusterReportSubscription, clusterChannelDict, 0)
+	if first == 0 {
+		return nil
+	}
+	time.Sleep(time.Duration(durationMs) * time.Millisecond)
+	second := ioReportCreateSamples(clusterReportSubscription, clusterChannelDict, 0)
+	if second == 0 {
+		cfRelease(first)
+		return nil
+	}
+	delta := ioReportCreateSamplesDelta(first, second, 0)
+	cfRelease(first)
+	cfRelease(second)
+	if delta == 0 {
+		return nil
+	}
+	defer cfRelease(delta)
+
+	var clusters []ClusterMetrics
+	count := cfArrayGetCount(delta)
+	for i := int64(0); i < count; i++ {
+		item := cfArrayGetValueAtIndex(delta, i)
+		name := cfStringToGo(ioReportChannelGetChannelName(item))
+		if name == "" {
+			continue
+		}
+		clusters = append(clusters, clusterFromStateChannel(name, item))
+	}
+	return clusters
+}
+
+// clusterFromStateChannel reads item's IOReportStateGetCount/
+// GetNameForIndex/GetResidency bins: a bin named "IDLE"/"DOWN"/"OFF" counts
+// toward IdleResidency, everything else toward ActiveResidency and (if its
+// name parses as an integer, the usual convention for a DVFS performance
+// state's bin label) the effective-frequency weighted sum.
+func clusterFromStateChannel(name string, item cfRef) ClusterMetrics {
+	cluster := ClusterMetrics{Name: name}
+	binCount := ioReportStateGetCount(item)
+	var freqWeighted, freqResidencyTotal float64
+	for idx := int32(0); idx < binCount; idx++ {
+		binName := cfStringToGo(ioReportStateGetNameForIndex(item, idx))
+		residency := float64(ioReportStateGetResidency(item, idx))
+		switch binName {
+		case "IDLE", "DOWN", "OFF":
+			cluster.IdleResidency += residency
+			continue
+		}
+		cluster.ActiveResidency += residency
+		if freqMHz, err := strconv.Atoi(binName); err == nil {
+			cluster.FreqBins = append(cluster.FreqBins, FreqBinResidency{FreqMHz: freqMHz, Residency: residency})
+			freqWeighted += float64(freqMHz) * residency
+			freqResidencyTotal += residency
+		}
+	}
+	total := cluster.ActiveResidency + cluster.IdleResidency
+	if total > 0 {
+		cluster.ActiveResidency /= total
+		cluster.IdleResidency /= total
+	}
+	if freqResidencyTotal > 0 {
+		cluster.EffectiveFreqMHz = freqWeighted / freqResidencyTotal
+	}
+	return cluster
+}
+
+// readSocTemp reads the SoC temperature sensor via the IOHIDEventSystem
+// client opened in initThermalHID, the same sensor path the cgo
+// implementation's getThermalState/samplePowerMetrics read.
+func readSocTemp() float32 {
+	if !hidInit || hidClient == 0 {
+		return 0
+	}
+	services := ioHIDEventSystemClientCopyServices(hidClient)
+	if services == 0 {
+		return 0
+	}
+	defer cfRelease(services)
+	count := cfArrayGetCount(services)
+	const kIOHIDEventTypeTemperature = 15
+	for i := int64(0); i < count; i++ {
+		service := ioHIDServiceClientCopyServiceAsUintptr(services, i)
+		event := ioHIDServiceClientCopyEvent(service, kIOHIDEventTypeTemperature, 0, 0)
+		if event == 0 {
+			continue
+		}
+		temp := ioHIDEventGetFloatValue(event, kIOHIDEventTypeTemperature<<16)
+		if temp > 0 {
+			return float32(temp)
+		}
+	}
+	return 0
+}
+
+func ioHIDServiceClientCopyServiceAsUintptr(services cfRef, idx int64) uintptr {
+	return uintptr(cfArrayGetValueAtIndex(services, idx))
+}
+
+// cfStringToGo reads a CFStringRef's contents the same way the cgo path
+// does when matching channel names, via CFStringGetCString.
+func cfStringToGo(s cfRef) string {
+	if s == 0 {
+		return ""
+	}
+	var cfStringGetLength func(s cfRef) int64
+	var cfStringGetCString func(s cfRef, buf *byte, bufSize int64, encoding uint32) bool
+	purego.RegisterLibFunc(&cfStringGetLength, coreFoundation, "CFStringGetLength")
+	purego.RegisterLibFunc(&cfStringGetCString, coreFoundation, "CFStringGetCString")
+
+	length := cfStringGetLength(s)
+	if length <= 0 {
+		return ""
+	}
+	buf := make([]byte, length*4+1) // worst case UTF-8 expansion, plus NUL
+	if !cfStringGetCString(s, &buf[0], int64(len(buf)), kCFStringEncodingUTF8) {
+		return ""
+	}
+	for i, b := range buf {
+		if b == 0 {
+			return string(buf[:i])
+		}
+	}
+	return string(buf)
+}
+
+func cleanupSocMetrics() {
+	if socChannelDict != 0 {
+		cfRelease(socChannelDict)
+		socChannelDict = 0
+	}
+	socReportSubscription = 0
+}
+
+func getSocThermalState() int {
+	temp := readSocTemp()
+	switch {
+	case temp >= 100:
+		return 3 // Critical
+	case temp >= 90:
+		return 2 // Serious
+	case temp >= 80:
+		return 1 // Fair
+	default:
+		return 0 // Nominal
+	}
+}
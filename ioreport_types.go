@@ -0,0 +1,45 @@
+//go:build darwin
+
+// Copyright (c) 2024-2026 Carsen Klock under MIT License
+// ioreport_types.go - SocMetrics is the public shape both IOReport backends
+// (ioreport_cgo.go and ioreport_purego.go) produce, so it has to live
+// outside both of their cgo/!cgo build tags.
+package main
+
+type SocMetrics struct {
+	CPUPower     float64 `json:"cpu_power"`
+	GPUPower     float64 `json:"gpu_power"`
+	ANEPower     float64 `json:"ane_power"`
+	DRAMPower    float64 `json:"dram_power"`
+	GPUSRAMPower float64 `json:"gpu_sram_power"`
+	SystemPower  float64 `json:"system_power"`
+	TotalPower   float64 `json:"total_power"`
+	GPUFreqMHz   int32   `json:"gpu_freq_mhz"`
+	GPUActive    float64 `json:"-"`
+	SocTemp      float32 `json:"soc_temp"`
+
+	// Clusters breaks the collapsed CPUPower/GPUPower totals above out per
+	// DVFS cluster (ECPU, PCPU0, PCPU1, GPUPH, ...), so asymmetric P/E-core
+	// behavior that a single "CPU power" number would hide is visible.
+	Clusters []ClusterMetrics `json:"clusters"`
+}
+
+// FreqBinResidency is one IOReport state-channel bin: how much of the
+// sample window (as a 0.0-1.0 fraction) a cluster spent at FreqMHz.
+type FreqBinResidency struct {
+	FreqMHz   int     `json:"freq_mhz"`
+	Residency float64 `json:"residency"`
+}
+
+// ClusterMetrics is one CPU or GPU DVFS cluster's power and residency for
+// the sample window, built from IOReportStateGetCount/GetNameForIndex/
+// GetResidency deltas between the two IOReportCreateSamples calls a sample
+// brackets.
+type ClusterMetrics struct {
+	Name             string             `json:"name"` // e.g. "ECPU", "PCPU0", "PCPU1", "GPUPH"
+	PowerW           float64            `json:"power_w"`
+	ActiveResidency  float64            `json:"active_residency"`
+	IdleResidency    float64            `json:"idle_residency"`
+	EffectiveFreqMHz float64            `json:"effective_freq_mhz"` // Σ(bin_freq_mhz * residency) / Σ(residency)
+	FreqBins         []FreqBinResidency `json:"freq_bins"`
+}
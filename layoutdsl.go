@@ -0,0 +1,264 @@
+// Copyright (c) 2024-2026 Carsen Klock under MIT License
+// layoutdsl.go - a gotop-style row/column layout grammar loaded from
+// ~/.config/mactop/layout.conf, plus a widget registry so layouts can be
+// composed from name tokens instead of hardcoded Go.
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	ui "github.com/gizak/termui/v3"
+)
+
+// widgetRegistry maps a layout DSL token to the widget it should place.
+// Registered lazily from setupUI since most entries reference widgets that
+// don't exist until the UI is built.
+var widgetRegistry = map[string]interface{}{}
+
+func registerWidget(name string, widget interface{}) {
+	widgetRegistry[name] = widget
+}
+
+// layoutPresets holds the built-in --layout options, written in the same DSL
+// a user's layout.conf would use. Rows are newline-separated; within a row,
+// whitespace separates columns; "name/weight" sets a column's relative width.
+var layoutPresets = map[string]string{
+	"default": ".2:alertbar\n" +
+		"cpu gpu\n" +
+		"ane/1 power/1 sparkline/1 mem/1\n" +
+		"model/1 net/1 cputime/1 sysstats/1\n" +
+		"procs",
+	"minimal": "cpu gpu\n" +
+		"procs",
+	"battery": "cpu gpu\n" +
+		"power sparkline\n" +
+		"battery\n" +
+		"procs",
+	"power": "cpu gpu\n" +
+		"power sparkline\n" +
+		"energytop\n" +
+		"procs",
+	"procs": "procs",
+}
+
+// parseLayoutDSL builds a termui grid from a layout spec string. Each
+// top-level line is a row of equal height (unless overridden with a leading
+// "N:" row-weight prefix, e.g. "2:cpu gpu" for a row twice as tall as a
+// default row); within a row, space-separated tokens are columns, each an
+// equal width unless given as "name/weight".
+func parseLayoutDSL(spec string) (*ui.Grid, error) {
+	lines := []string{}
+	for _, l := range strings.Split(spec, "\n") {
+		l = strings.TrimSpace(l)
+		if l != "" {
+			lines = append(lines, l)
+		}
+	}
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("layout spec has no rows")
+	}
+
+	rowWeights := make([]float64, len(lines))
+	rowTokens := make([][]string, len(lines))
+	totalWeight := 0.0
+	for i, line := range lines {
+		weight := 1.0
+		if idx := strings.Index(line, ":"); idx != -1 && idx < 3 {
+			if w, err := strconv.ParseFloat(line[:idx], 64); err == nil {
+				weight = w
+				line = line[idx+1:]
+			}
+		}
+		rowWeights[i] = weight
+		totalWeight += weight
+		rowTokens[i] = strings.Fields(line)
+		if len(rowTokens[i]) == 0 {
+			return nil, fmt.Errorf("layout row %d has no widgets", i+1)
+		}
+		if weight <= 0 {
+			return nil, fmt.Errorf("layout row %d has non-positive weight %g", i+1, weight)
+		}
+	}
+
+	grid := ui.NewGrid()
+	var rows []interface{}
+	for i, tokens := range rowTokens {
+		cols, err := layoutCols(tokens)
+		if err != nil {
+			return nil, err
+		}
+		rowArgs := append([]interface{}{rowWeights[i] / totalWeight}, cols...)
+		rows = append(rows, callNewRow(rowArgs))
+	}
+	grid.Set(rows...)
+	return grid, nil
+}
+
+// layoutCols builds one row's columns. Weights are normalized against each
+// other (so "cpu/1 gpu/1" and "cpu/2 gpu/2" are equivalent) rather than
+// required to sum to 1.0 - a stricter sum-to-1.0 check would only reject
+// configs this normalization already handles correctly, so the validation
+// here instead catches the two things that actually panic inside
+// grid.Set: an empty row, and a widget name not in widgetRegistry.
+func layoutCols(tokens []string) ([]interface{}, error) {
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("layout row has no widgets")
+	}
+	colWeights := make([]float64, len(tokens))
+	names := make([]string, len(tokens))
+	total := 0.0
+	for i, tok := range tokens {
+		name, weight := tok, 1.0
+		if idx := strings.LastIndex(tok, "/"); idx != -1 {
+			if w, err := strconv.ParseFloat(tok[idx+1:], 64); err == nil {
+				weight = w
+				name = tok[:idx]
+			}
+		}
+		if weight <= 0 {
+			return nil, fmt.Errorf("layout widget %q has non-positive weight %g", name, weight)
+		}
+		names[i] = name
+		colWeights[i] = weight
+		total += weight
+	}
+	cols := make([]interface{}, len(tokens))
+	for i, name := range names {
+		widget, ok := widgetRegistry[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown widget %q in layout", name)
+		}
+		cols[i] = ui.NewCol(colWeights[i]/total, widget)
+	}
+	return cols, nil
+}
+
+// callNewRow builds a ui.GridItem via ui.NewRow, taking the row's height
+// fraction followed by its column items.
+func callNewRow(args []interface{}) ui.GridItem {
+	height := args[0].(float64)
+	return ui.NewRow(height, args[1:]...)
+}
+
+// layoutConfPaths are checked in order for a user layout file: the XDG-style
+// path mactop's own config lives under, then the older ~/.mactop convention.
+func layoutConfPaths(homeDir string) []string {
+	return []string{
+		filepath.Join(homeDir, ".config", "mactop", "layout.conf"),
+		filepath.Join(homeDir, ".mactop", "layout.conf"),
+	}
+}
+
+// loadLayoutFile reads a user layout.conf, if present, and parses it as one
+// or more named layouts in INI-style sections:
+//
+//	[main]
+//	2:cpu 1:gpu
+//	disk mem
+//	3:power procs
+//
+//	[minimal]
+//	cpu
+//	procs
+//
+// A file with no "[name]" section headers is a single layout named "default".
+// Returns the layouts in file order, plus their names in that same order so
+// the 'l' key can cycle through them.
+func loadLayoutFile() (map[string]string, []string, bool) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, nil, false
+	}
+	for _, path := range layoutConfPaths(homeDir) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		layouts, order := parseLayoutFile(string(data))
+		if len(layouts) > 0 {
+			return layouts, order, true
+		}
+	}
+	return nil, nil, false
+}
+
+// parseLayoutFile splits layout.conf content into named layouts.
+func parseLayoutFile(content string) (map[string]string, []string) {
+	layouts := map[string]string{}
+	var order []string
+	name := "default"
+	var body strings.Builder
+
+	flush := func() {
+		if spec := strings.TrimSpace(body.String()); spec != "" {
+			if _, exists := layouts[name]; !exists {
+				order = append(order, name)
+			}
+			layouts[name] = spec
+		}
+		body.Reset()
+	}
+
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]") {
+			flush()
+			name = strings.TrimSpace(trimmed[1 : len(trimmed)-1])
+			continue
+		}
+		body.WriteString(line)
+		body.WriteString("\n")
+	}
+	flush()
+	return layouts, order
+}
+
+// pluginLayoutPresets holds layouts a plugin registered via
+// pluginapi.Registry.RegisterLayoutPreset, alongside the built-in
+// layoutPresets; pluginLayoutPresetOrder tracks registration order for 'l'
+// cycling. See registryImpl.RegisterLayoutPreset in plugin.go.
+var (
+	pluginLayoutPresets     = map[string]string{}
+	pluginLayoutPresetOrder []string
+)
+
+// registerPluginLayoutPreset adds a plugin-provided named layout, rejecting
+// a name already claimed by a built-in preset or an existing plugin preset;
+// a user's layout.conf section of the same name still wins in
+// resolveLayoutSpec, since plugins are additive and shouldn't override a
+// user's own config.
+func registerPluginLayoutPreset(name, spec string) error {
+	if _, ok := layoutPresets[name]; ok {
+		return fmt.Errorf("layout preset %q is already a built-in", name)
+	}
+	if _, ok := pluginLayoutPresets[name]; ok {
+		return fmt.Errorf("layout preset %q is already registered by a plugin", name)
+	}
+	pluginLayoutPresets[name] = spec
+	pluginLayoutPresetOrder = append(pluginLayoutPresetOrder, name)
+	return nil
+}
+
+// resolveLayoutSpec picks a layout.conf spec by name if present, else the
+// named built-in preset, else a plugin-registered preset, else "default".
+func resolveLayoutSpec(preset string) string {
+	if layouts, _, ok := loadLayoutFile(); ok {
+		if spec, ok := layouts[preset]; ok {
+			return spec
+		}
+		if spec, ok := layouts["default"]; ok {
+			return spec
+		}
+	}
+	if spec, ok := layoutPresets[preset]; ok {
+		return spec
+	}
+	if spec, ok := pluginLayoutPresets[preset]; ok {
+		return spec
+	}
+	return layoutPresets["default"]
+}
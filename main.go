@@ -2,14 +2,6 @@
 // mactop is a simple terminal based Apple Silicon power monitor written in Go Lang! github.com/context-labs/mactop
 package main
 
-/*
-#include <mach/mach_host.h>
-#include <mach/processor_info.h>
-#include <mach/mach_init.h>
-
-extern kern_return_t vm_deallocate(vm_map_t target_task, vm_address_t address, vm_size_t size);
-*/
-import "C"
 import (
 	"fmt"
 	"image"
@@ -18,31 +10,32 @@ import (
 	"os/exec"
 	"os/signal"
 	"path/filepath"
-	"runtime"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
 	"sync"
 	"syscall"
 	"time"
-	"unsafe"
-
-	"net/http"
 
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
 
 	ui "github.com/gizak/termui/v3"
 	w "github.com/gizak/termui/v3/widgets"
 	"github.com/shirou/gopsutil/v4/disk"
+	"github.com/shirou/gopsutil/v4/load"
 	"github.com/shirou/gopsutil/v4/mem"
 	"github.com/shirou/gopsutil/v4/net"
+	"golang.org/x/sys/unix"
+
+	"github.com/context-labs/mactop/v2/internal/render"
 )
 
 var (
 	version                                      = "v0.2.5"
 	cpuGauge, gpuGauge, memoryGauge, aneGauge    *w.Gauge
 	modelText, PowerChart, NetworkInfo, helpText *w.Paragraph
+	cpuTimeWidget                                *w.Paragraph // second CPU widget: user/system/idle/nice breakdown, see updateCPUTimeUI
 	grid                                         *ui.Grid
 	processList                                  *w.List
 	sparkline, gpuSparkline                      *w.Sparkline
@@ -51,7 +44,10 @@ var (
 	powerValues                                  = make([]float64, 35)
 	lastUpdateTime                               time.Time
 	stderrLogger                                 = log.New(os.Stderr, "", 0)
-	currentGridLayout                            = "default"
+	currentLayoutPreset                          = "default"
+	layoutPresetOrder                            = []string{"default", "minimal", "battery", "power", "procs"}
+	layoutFlag                                   = "default"
+	colorSchemePath                              string
 	showHelp, partyMode                          = false, false
 	updateInterval                               = 1000
 	done                                         = make(chan struct{})
@@ -59,20 +55,61 @@ var (
 	colorOptions                                 = []ui.Color{ui.ColorWhite, ui.ColorGreen, ui.ColorBlue, ui.ColorCyan, ui.ColorMagenta, ui.ColorYellow, ui.ColorRed}
 	partyTicker                                  *time.Ticker
 	lastCPUTimes                                 []CPUUsage
+	lastCPUTimePct                               []CPUUsage // per-core user/system/idle/nice as %% of the tick, for cpuTimeWidget and mactop_cpu_time_seconds_total
+	lastCPUTimeDeltaSecs                         []CPUUsage // per-core user/system/idle/nice seconds elapsed this tick, for the same counter
 	firstRun                                     = true
 	sortReverse                                  = false
-	columns                                      = []string{"PID", "USER", "VIRT", "RES", "CPU", "MEM", "TIME", "CMD"}
+	columns                                      = []string{"PID", "USER", "VIRT", "RES", "CPU", "MEM", "POWER", "ENERGY", "TIME", "CMD"}
 	selectedColumn                               = 4
+	sortedProcesses                              []ProcessMetrics // last rendered order, so the list's selection maps to a PID for kill keybinds
 	maxPowerSeen                                 = 0.1
+	lastPackageWatts                             float64 // most recent package power, for attributeProcessPower
 	gpuValues                                    = make([]float64, 100)
 	prometheusPort                               string
+	apiSocketPath                                string   // --api-socket: also (or only, if --prometheus was never set) bind the API mux to this Unix socket path
+	headlessFormat                               = "json" // --headless-format: json (default), openmetrics, or influx-lineproto, see headless.go
+	apiToken                                     string   // --api-token: required as "Authorization: Bearer <token>" on the TCP API listener; the Unix socket is never gated
 	ttyFile                                      *os.File
-	lastNetStats                                 net.IOCountersStat
-	lastDiskStats                                disk.IOCountersStat
+	lastNetStats                                 = map[string]net.IOCountersStat{}
+	lastDiskStats                                = map[string]disk.IOCountersStat{}
 	lastNetDiskTime                              time.Time
 	netDiskMutex                                 sync.Mutex
+	netIfaceFilter                               []string // --net-iface: comma-separated interface allowlist, empty means all
+	diskDeviceFilter                             []string // --disk-device: comma-separated device allowlist, empty means all
+	batteryGauge                                 *w.Gauge
+	batterySparkline                             *w.Sparkline
+	batterySparklineGroup                        *w.SparklineGroup
+	batteryValues                                = make([]float64, 50)
+	batteryPresent                               = false
+	noBattery                                    = false
+	powerBrailleSparkline, gpuBrailleSparkline   *BrailleSparkline
+	powerBrailleGraph, gpuBrailleGraph           *BrailleGraph
+	recordPath, replayPath                       string
+	replaySpeed                                  = 1.0
+	activeRecorder                               *Recorder
+	replayedProcesses                            []ProcessMetrics
+	replaySeekChan                               = make(chan int, 1)
+	replayPauseChan                              = make(chan struct{}, 1)
+	chipModelName                                string
 )
 
+// powerHistoryWidget and gpuHistoryWidget return whichever chart widget
+// --render selected, so grid setup doesn't need to branch on renderMode
+// at every call site.
+func powerHistoryWidget() interface{} {
+	if renderMode == "braille" && powerBrailleGraph != nil {
+		return powerBrailleGraph
+	}
+	return sparklineGroup
+}
+
+func gpuHistoryWidget() interface{} {
+	if renderMode == "braille" && gpuBrailleGraph != nil {
+		return gpuBrailleGraph
+	}
+	return gpuSparklineGroup
+}
+
 var (
 	cpuUsage = prometheus.NewGauge(
 		prometheus.GaugeOpts{
@@ -95,6 +132,47 @@ var (
 		},
 	)
 
+	// cpuCoreUsage is the per-core complement to the E/P cluster averages
+	// above, labeled the same way cpuCoreWidget's own labels are (cluster
+	// "E"/"P", core the index within that cluster).
+	cpuCoreUsage = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "mactop_cpu_core_usage_percent",
+			Help: "Current per-core CPU usage percentage, labeled by cluster (E or P) and core index within it",
+		},
+		[]string{"cluster", "core"},
+	)
+
+	cpuTimeSecondsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "mactop_cpu_time_seconds_total",
+			Help: "Cumulative CPU time in seconds, by mode and core, derived from host_processor_info tick deltas (core=\"all\" is the cross-core sum)",
+		},
+		[]string{"mode", "core"},
+	)
+
+	loadAverage = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "mactop_load_average",
+			Help: "System load average, by window (1m, 5m, 15m)",
+		},
+		[]string{"window"},
+	)
+
+	memoryPressure = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "mactop_memory_pressure",
+			Help: "macOS memory-pressure state: 0=normal, 1=warn, 2=critical",
+		},
+	)
+
+	sampleIntervalGauge = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "mactop_sample_interval_ms",
+			Help: "Effective process/net/disk sampling interval in milliseconds (== --interval unless --adaptive has backed it off)",
+		},
+	)
+
 	gpuUsage = prometheus.NewGauge(
 		prometheus.GaugeOpts{
 			Name: "mactop_gpu_usage_percent",
@@ -109,6 +187,20 @@ var (
 		},
 	)
 
+	gpuActiveRatio = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "mactop_gpu_active_ratio",
+			Help: "Current GPU active residency, 0.0-1.0",
+		},
+	)
+
+	// powerUsage is labeled by the domains this codebase's IOReport sampling
+	// actually breaks out (cpu, gpu, ane, dram, total); it has no gpu_sram or
+	// system domain because samplePowerMetrics never reads those channels.
+	// Per-domain names like mactop_cpu_power_watts/mactop_ane_power_watts are
+	// deliberately not minted alongside this: {component=...} already gives a
+	// PromQL consumer the same selectivity, and a second metric family would
+	// just be this one duplicated.
 	powerUsage = prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "mactop_power_watts",
@@ -131,6 +223,14 @@ var (
 		},
 	)
 
+	thermalPressure = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "mactop_thermal_pressure",
+			Help: "1 for the thermal level mactop currently reads as active, 0 otherwise, by level.",
+		},
+		[]string{"level"},
+	)
+
 	memoryUsage = prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "mactop_memory_gb",
@@ -147,6 +247,14 @@ var (
 		[]string{"direction"},
 	)
 
+	networkBitsPerSec = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "mactop_network_bits_per_sec",
+			Help: "Network speed in bits/s",
+		},
+		[]string{"direction"},
+	)
+
 	diskIOSpeed = prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "mactop_disk_kbytes_per_sec",
@@ -162,32 +270,98 @@ var (
 		},
 		[]string{"operation"},
 	)
+
+	networkSpeedByIface = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "mactop_network_iface_kbytes_per_sec",
+			Help: "Network speed in KB/s, broken down per interface (subject to --net-iface)",
+		},
+		[]string{"interface", "direction"},
+	)
+
+	diskIOSpeedByDevice = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "mactop_disk_device_kbytes_per_sec",
+			Help: "Disk I/O speed in KB/s, broken down per device (subject to --disk-device)",
+		},
+		[]string{"device", "operation"},
+	)
+
+	processPowerWatts = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "mactop_process_power_watts",
+			Help: "Estimated power draw of the top processes by CPU share, see attributeProcessPower",
+		},
+		[]string{"pid", "command"},
+	)
 )
 
-func startPrometheusServer(port string) {
+// processPowerTopN bounds how many processes get their own
+// mactop_process_power_watts series, so a busy process list doesn't blow up
+// Prometheus cardinality.
+const processPowerTopN = 10
+
+// newAPIRegistry builds the Prometheus registry backing both /metrics and
+// the mactop_* series the other API endpoints derive their JSON from.
+// Split out of startAPIServer (api.go) only because that file needs the
+// stdlib "net" package for its Unix-socket listener, which this file
+// shadows with gopsutil's net package for NetworkInfo's IOCountersStat.
+// startPromServices builds the shared Prometheus registry and, if
+// --prometheus/--api-socket were given, starts the API server around it.
+// Returns nil if nothing asked for Prometheus data (neither --prometheus,
+// --api-socket, nor an --exporter mode that publishes it), so the interactive
+// and --exporter-only startup paths can share one code path rather than each
+// deciding separately whether a registry needs to exist.
+func startPromServices() *prometheus.Registry {
+	if prometheusPort == "" && apiSocketPath == "" && exporterFlag != exporterPrometheus && exporterFlag != exporterBoth {
+		return nil
+	}
+	registry := newAPIRegistry()
+	if prometheusPort != "" || apiSocketPath != "" {
+		startAPIServer(prometheusPort, apiSocketPath, registry)
+		if prometheusPort != "" {
+			stderrLogger.Printf("Prometheus metrics and API available at http://localhost:%s/metrics\n", prometheusPort)
+		}
+		if apiSocketPath != "" {
+			stderrLogger.Printf("API also available on Unix socket %s\n", apiSocketPath)
+		}
+	}
+	return registry
+}
+
+func newAPIRegistry() *prometheus.Registry {
 	registry := prometheus.NewRegistry()
 	registry.MustRegister(cpuUsage)
 	registry.MustRegister(ecoreUsage)
 	registry.MustRegister(pcoreUsage)
+	registry.MustRegister(cpuCoreUsage)
+	registry.MustRegister(cpuTimeSecondsTotal)
+	registry.MustRegister(loadAverage)
+	registry.MustRegister(memoryPressure)
+	registry.MustRegister(sampleIntervalGauge)
 	registry.MustRegister(gpuUsage)
 	registry.MustRegister(gpuFreqMHz)
+	registry.MustRegister(gpuActiveRatio)
+	registry.MustRegister(thermalPressure)
 	registry.MustRegister(powerUsage)
 	registry.MustRegister(socTemp)
 	registry.MustRegister(thermalState)
 	registry.MustRegister(memoryUsage)
 	registry.MustRegister(networkSpeed)
+	registry.MustRegister(networkBitsPerSec)
 	registry.MustRegister(diskIOSpeed)
+	registry.MustRegister(alertsTotal)
+	registry.MustRegister(alertFiring)
+	registry.MustRegister(thresholdEventsTotal)
 	registry.MustRegister(diskIOPS)
-
-	handler := promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
-
-	http.Handle("/metrics", handler)
-	go func() {
-		err := http.ListenAndServe(":"+port, nil)
-		if err != nil {
-			stderrLogger.Printf("Failed to start Prometheus metrics server: %v\n", err)
-		}
-	}()
+	registry.MustRegister(networkSpeedByIface)
+	registry.MustRegister(diskIOSpeedByDevice)
+	registry.MustRegister(processPowerWatts)
+	registry.MustRegister(energyJoulesTotal)
+	registry.MustRegister(processEnergyJoulesTotal)
+	registry.MustRegister(uptimeGauge)
+	registry.MustRegister(loggedInUsersGauge)
+	return registry
 }
 
 type CPUUsage struct {
@@ -205,10 +379,28 @@ type CPUMetrics struct {
 	CoreUsages                                                       []float64
 	Throttled                                                        bool
 	SocTemp                                                          float64
+	Clusters                                                         []ClusterMetrics // per-DVFS-cluster power/residency, see ioreport_types.go
 }
 
 type NetDiskMetrics struct {
 	OutPacketsPerSec, OutBytesPerSec, InPacketsPerSec, InBytesPerSec, ReadOpsPerSec, WriteOpsPerSec, ReadKBytesPerSec, WriteKBytesPerSec float64
+	PerInterface                                                                                                                         map[string]IfaceRate
+	PerDevice                                                                                                                            map[string]DiskRate
+}
+
+// IfaceRate is one network interface's rates, the per-name breakdown behind
+// NetDiskMetrics.PerInterface; the aggregate *BytesPerSec/*PacketsPerSec
+// fields above remain the sum across every tracked interface, filtered by
+// --net-iface if set.
+type IfaceRate struct {
+	InBytesPerSec, OutBytesPerSec, InPacketsPerSec, OutPacketsPerSec float64
+}
+
+// DiskRate is one disk device's rates, the per-name breakdown behind
+// NetDiskMetrics.PerDevice; ReadKBytesPerSec/WriteKBytesPerSec above remain
+// the sum across every tracked device, filtered by --disk-device if set.
+type DiskRate struct {
+	ReadKBytesPerSec, WriteKBytesPerSec, ReadOpsPerSec, WriteOpsPerSec float64
 }
 
 type GPUMetrics struct {
@@ -221,6 +413,9 @@ type ProcessMetrics struct {
 	VSZ, RSS                                 int64
 	User, TTY, State, Started, Time, Command string
 	LastUpdated                              time.Time
+	PowerWatts                               float64 // estimated, see attributeProcessPower
+	EnergyMJ                                 float64 // cumulative estimate in mJ, see accumulateProcessEnergy
+	Group                                    string  // launchd job label or bare command, see groupProcesses; "" when grouping is off
 }
 
 type MemoryMetrics struct {
@@ -239,8 +434,20 @@ type CPUCoreWidget struct {
 	labels                 []string
 	eCoreCount, pCoreCount int
 	modelName              string
+	// history holds each core's recent usage samples, newest last, for
+	// cpuGraphMode "braille"; capped at maxCoreHistory since no terminal is
+	// wide enough to need more than that many dot-columns.
+	history [][]float64
 }
 
+// cpuGraphMode selects how CPUCoreWidget.Draw renders each core's usage:
+// "bar" (default, one block-character bar per core) or "braille" (a
+// higher-resolution usage history line using render.BrailleCanvas). Set via
+// --cpu-graph-mode and cycled at runtime with 'b' (see cycleCPUGraphMode).
+var cpuGraphMode = "bar"
+
+const maxCoreHistory = 512
+
 func NewEventThrottler(gracePeriod time.Duration) *EventThrottler {
 	return &EventThrottler{
 		timer:       nil,
@@ -282,62 +489,45 @@ func GetCPUPercentages() ([]float64, error) {
 		return make([]float64, len(currentTimes)), nil
 	}
 	percentages := make([]float64, len(currentTimes))
+	timePct := make([]CPUUsage, len(currentTimes))
+	timeDeltaSecs := make([]CPUUsage, len(currentTimes))
 	for i := range currentTimes {
-		totalDelta := (currentTimes[i].User - lastCPUTimes[i].User) +
-			(currentTimes[i].System - lastCPUTimes[i].System) +
-			(currentTimes[i].Idle - lastCPUTimes[i].Idle) +
-			(currentTimes[i].Nice - lastCPUTimes[i].Nice)
-
-		activeDelta := (currentTimes[i].User - lastCPUTimes[i].User) +
-			(currentTimes[i].System - lastCPUTimes[i].System) +
-			(currentTimes[i].Nice - lastCPUTimes[i].Nice)
+		userDelta := currentTimes[i].User - lastCPUTimes[i].User
+		systemDelta := currentTimes[i].System - lastCPUTimes[i].System
+		idleDelta := currentTimes[i].Idle - lastCPUTimes[i].Idle
+		niceDelta := currentTimes[i].Nice - lastCPUTimes[i].Nice
+		totalDelta := userDelta + systemDelta + idleDelta + niceDelta
+		activeDelta := userDelta + systemDelta + niceDelta
 
 		if totalDelta > 0 {
 			percentages[i] = (activeDelta / totalDelta) * 100.0
+			timePct[i] = CPUUsage{
+				User:   userDelta / totalDelta * 100.0,
+				System: systemDelta / totalDelta * 100.0,
+				Idle:   idleDelta / totalDelta * 100.0,
+				Nice:   niceDelta / totalDelta * 100.0,
+			}
 		}
 		if percentages[i] < 0 {
 			percentages[i] = 0
 		} else if percentages[i] > 100 {
 			percentages[i] = 100
 		}
+		// host_processor_info ticks are counted at the traditional 100/sec
+		// (USER_HZ) rate, same assumption ps/top make when reporting CPU time.
+		timeDeltaSecs[i] = CPUUsage{
+			User:   userDelta / 100.0,
+			System: systemDelta / 100.0,
+			Idle:   idleDelta / 100.0,
+			Nice:   niceDelta / 100.0,
+		}
 	}
+	lastCPUTimePct = timePct
+	lastCPUTimeDeltaSecs = timeDeltaSecs
 	lastCPUTimes = currentTimes
 	return percentages, nil
 }
 
-func GetCPUUsage() ([]CPUUsage, error) {
-	var numCPUs C.natural_t
-	var cpuLoad *C.processor_cpu_load_info_data_t
-	var cpuMsgCount C.mach_msg_type_number_t
-	host := C.mach_host_self()
-	kernReturn := C.host_processor_info(
-		host,
-		C.PROCESSOR_CPU_LOAD_INFO,
-		&numCPUs,
-		(*C.processor_info_array_t)(unsafe.Pointer(&cpuLoad)),
-		&cpuMsgCount,
-	)
-	if kernReturn != C.KERN_SUCCESS {
-		return nil, fmt.Errorf("error getting CPU info: %d", kernReturn)
-	}
-	defer C.vm_deallocate(
-		C.mach_task_self_,
-		(C.vm_address_t)(uintptr(unsafe.Pointer(cpuLoad))),
-		C.vm_size_t(cpuMsgCount)*C.sizeof_processor_cpu_load_info_data_t,
-	)
-	cpuLoadInfo := (*[1 << 30]C.processor_cpu_load_info_data_t)(unsafe.Pointer(cpuLoad))[:numCPUs:numCPUs]
-	cpuUsage := make([]CPUUsage, numCPUs)
-	for i := 0; i < int(numCPUs); i++ {
-		cpuUsage[i] = CPUUsage{
-			User:   float64(cpuLoadInfo[i].cpu_ticks[C.CPU_STATE_USER]),
-			System: float64(cpuLoadInfo[i].cpu_ticks[C.CPU_STATE_SYSTEM]),
-			Idle:   float64(cpuLoadInfo[i].cpu_ticks[C.CPU_STATE_IDLE]),
-			Nice:   float64(cpuLoadInfo[i].cpu_ticks[C.CPU_STATE_NICE]),
-		}
-	}
-	return cpuUsage, nil
-}
-
 func NewCPUCoreWidget(modelInfo map[string]interface{}) *CPUCoreWidget {
 	eCoreCount, _ := modelInfo["e_core_count"].(int)
 	pCoreCount, _ := modelInfo["p_core_count"].(int)
@@ -359,12 +549,34 @@ func NewCPUCoreWidget(modelInfo map[string]interface{}) *CPUCoreWidget {
 		eCoreCount: eCoreCount,
 		pCoreCount: pCoreCount,
 		modelName:  modelName,
+		history:    make([][]float64, totalCores),
 	}
 }
 
 func (w *CPUCoreWidget) UpdateUsage(usage []float64) {
 	w.cores = make([]float64, len(usage))
 	copy(w.cores, usage)
+
+	if len(w.history) != len(usage) {
+		w.history = make([][]float64, len(usage))
+	}
+	for i, u := range usage {
+		w.history[i] = append(w.history[i], u)
+		if len(w.history[i]) > maxCoreHistory {
+			w.history[i] = w.history[i][len(w.history[i])-maxCoreHistory:]
+		}
+	}
+}
+
+// cycleCPUGraphMode toggles CPUCoreWidget.Draw between "bar" and "braille"
+// rendering, bound to 'b'. Unlike --render (which only governs the
+// power/GPU history braille graphs), this affects the per-core widget only.
+func cycleCPUGraphMode() {
+	if cpuGraphMode == "braille" {
+		cpuGraphMode = "bar"
+	} else {
+		cpuGraphMode = "braille"
+	}
 }
 
 func (w *CPUCoreWidget) Draw(buf *ui.Buffer) {
@@ -422,41 +634,89 @@ func (w *CPUCoreWidget) Draw(buf *ui.Buffer) {
 			continue
 		}
 
-		usedWidth := int((usage / 100.0) * float64(availWidth-7))
-
-		buf.SetString("[", ui.NewStyle(ui.ColorWhite),
+		buf.SetString("[", ui.NewStyle(resolveColor(activeColorScheme.Bracket)),
 			image.Pt(x+labelWidth, y))
 
-		for bx := 0; bx < availWidth-7; bx++ {
-			char := " "
-			var color ui.Color
-			if bx < usedWidth {
-				char = "❚"
-				switch {
-				case usage >= 60:
-					color = ui.ColorRed
-				case usage >= 40:
-					color = ui.ColorYellow
-				case usage >= 30:
-					color = ui.ColorCyan
-				default:
+		barCells := availWidth - 7
+		if cpuGraphMode == "braille" {
+			w.drawCoreBraille(buf, actualIndex, barCells, themeColor, x+labelWidth+1, y)
+		} else {
+			usedWidth := int((usage / 100.0) * float64(barCells))
+			for bx := 0; bx < barCells; bx++ {
+				char := " "
+				var color ui.Color
+				if bx < usedWidth {
+					char = "❚"
+					switch {
+					case usage >= 60:
+						color = resolveColor(activeColorScheme.CPUHigh)
+					case usage >= 40:
+						color = resolveColor(activeColorScheme.CPUMid)
+					case usage >= 30:
+						color = resolveColor(activeColorScheme.CPULow)
+					default:
+						color = themeColor
+					}
+				} else {
 					color = themeColor
 				}
-			} else {
-				color = themeColor
+				buf.SetString(char, ui.NewStyle(color),
+					image.Pt(x+labelWidth+1+bx, y))
 			}
-			buf.SetString(char, ui.NewStyle(color),
-				image.Pt(x+labelWidth+1+bx, y))
 		}
 		percentage := fmt.Sprintf("%5.1f%%", usage)
 		buf.SetString(percentage, ui.NewStyle(245),
 			image.Pt(x+labelWidth+availWidth-7, y))
 
-		buf.SetString("]", ui.NewStyle(ui.ColorWhite),
+		buf.SetString("]", ui.NewStyle(resolveColor(activeColorScheme.Bracket)),
 			image.Pt(x+labelWidth+availWidth-1, y))
 	}
 }
 
+// drawCoreBraille renders core's usage history into a cells-wide, 1-cell-tall
+// braille strip starting at (startX, y): 2 samples per cell horizontally, 4
+// usage levels per cell vertically (render.BrailleCanvas), colored by the
+// max usage within the visible window using the same thresholds the bar
+// mode uses.
+func (w *CPUCoreWidget) drawCoreBraille(buf *ui.Buffer, core, cells int, themeColor ui.Color, startX, y int) {
+	if cells < 1 || core >= len(w.history) {
+		return
+	}
+	hist := w.history[core]
+	dotWidth := cells * 2
+	start := 0
+	if len(hist) > dotWidth {
+		start = len(hist) - dotWidth
+	}
+	visible := hist[start:]
+
+	canvas := render.NewBrailleCanvas(cells, 1)
+	canvas.PlotLine(visible, 100.0)
+
+	maxUsage := 0.0
+	for _, v := range visible {
+		if v > maxUsage {
+			maxUsage = v
+		}
+	}
+	var color ui.Color
+	switch {
+	case maxUsage >= 60:
+		color = resolveColor(activeColorScheme.CPUHigh)
+	case maxUsage >= 40:
+		color = resolveColor(activeColorScheme.CPUMid)
+	case maxUsage >= 30:
+		color = resolveColor(activeColorScheme.CPULow)
+	default:
+		color = themeColor
+	}
+	style := ui.NewStyle(color)
+	row := canvas.Render()[0]
+	for dx, r := range row {
+		buf.SetCell(ui.NewCell(r, style), image.Pt(startX+dx, y))
+	}
+}
+
 func setupUI() {
 	appleSiliconModel := getSOCInfo()
 	modelText, helpText = w.NewParagraph(), w.NewParagraph()
@@ -485,35 +745,87 @@ func setupUI() {
 		pCoreCount,
 		gpuCoreCount,
 	)
+	chipModelName = modelName
 	prometheusStatus := "Disabled"
 	if prometheusPort != "" {
 		prometheusStatus = fmt.Sprintf("Enabled (Port: %s)", prometheusPort)
 	}
+	adaptiveStatus := adaptiveStatusText()
 	helpText.Text = fmt.Sprintf(
-		"mactop is open source monitoring tool for Apple Silicon authored by Carsen Klock in Go Lang!\n\n"+
+		tr("help.intro")+"\n\n"+
 			"Repo: github.com/context-labs/mactop\n\n"+
 			"Prometheus Metrics: %s\n\n"+
-			"Controls:\n"+
-			"- r: Refresh the UI data manually\n"+
-			"- c: Cycle through UI color themes\n"+
-			"- p: Toggle party mode (color cycling)\n"+
-			"- l: Toggle the main display's layout\n"+
-			"- h or ?: Toggle this help menu\n"+
-			"- q or <C-c>: Quit the application\n\n"+
+			"Adaptive Sampling: %s\n\n"+
+			tr("help.controls")+"\n"+
+			"- "+tr("help.control.refresh")+"\n"+
+			"- "+tr("help.control.theme")+"\n"+
+			"- "+tr("help.control.party")+"\n"+
+			"- "+tr("help.control.layout")+"\n"+
+			"- "+tr("help.control.bandwidth")+"\n"+
+			"- "+tr("help.control.alerts")+"\n"+
+			"- "+tr("help.control.help")+"\n"+
+			"- "+tr("help.control.quit")+"\n"+
+			"- Press 't' to SIGTERM, or 'K' to SIGKILL, the process selected in the process list.\n"+
+			"- Press 'P' to sort the process list by POWER (estimated per-process wattage, see --attribute-pid and mactop_process_power_watts).\n\n"+
 			"Start Flags:\n"+
 			"--help, -h: Show this help menu\n"+
 			"--version, -v: Show the version of mactop\n"+
 			"--interval, -i: Set the update interval in milliseconds. Default is 1000.\n"+
+			"--adaptive[=on|off]: Back off the process-list and net/disk collectors (up to --max-interval) after several idle ticks (low package watts, low peak core%%, nominal thermal state), snapping back to --min-interval the instant either spikes or a key is pressed. The cheap power/thermal sampler always stays at --interval. Bare --adaptive means on. Off by default.\n"+
+			"--min-interval: Floor, in milliseconds, for the adaptive process/net/disk interval. Default is --interval's value.\n"+
+			"--max-interval: Ceiling, in milliseconds, for the adaptive process/net/disk interval. Default is 8x --interval.\n"+
+			"--headless-format: Output format for --exporter-only's per-tick stdout stream: 'json' (default), 'openmetrics', or 'influx-lineproto' (also accepts 'influx'), for piping straight into telegraf exec or promtail without a scrape endpoint.\n"+
+			"--net-iface: Comma-separated list of network interfaces to track (e.g. --net-iface=en0,en5). Default is all interfaces.\n"+
+			"--disk-device: Comma-separated list of disk devices to track (e.g. --disk-device=disk0). Default is all devices.\n"+
 			"--prometheus, -p: Set and enable a Prometheus metrics port. Default is none. (e.g. --prometheus=9090)\n"+
-			"--color, -c: Set the UI color. Default is none. Options are 'green', 'red', 'blue', 'cyan', 'magenta', 'yellow', and 'white'.\n\n"+
+			"--color, -c: Set the UI color. Default is none. Options are 'green', 'red', 'blue', 'cyan', 'magenta', 'yellow', and 'white'.\n"+
+			"--no-battery: Disable the battery widget, for desktops (Mac Studio/Mini) with no battery.\n"+
+			"--exporter: Run a metrics exporter alongside the TUI. Options are 'prometheus', 'otlp', or 'both'.\n"+
+			"--listen: Address for the Prometheus exporter. Default is :9101.\n"+
+			"--otlp-endpoint: OTLP/HTTP collector endpoint for the otlp exporter.\n"+
+			"--otlp-headers: Extra headers for the OTLP exporter's requests, e.g. 'Authorization=Bearer <token>,X-Org=abc'. Comma-separated key=value pairs.\n"+
+			"--otlp-interval, --export-interval: Push interval for the OTLP exporter. Default is 10s.\n"+
+			"--exporter-only: Run the exporter(s) without the termui dashboard, e.g. under launchd.\n"+
+			"--render: Sparkline/graph rendering backend. Options are 'blocks' (default) or 'braille'.\n"+
+			"--cpu-graph-mode: CPUCoreWidget per-core rendering. 'bar' (default, one block-character bar per core) or 'braille' (a higher-resolution usage history line via the same braille canvas --render=braille uses for the power/GPU graphs, 2 samples per cell horizontally). Toggle at runtime with 'b'.\n"+
+			"--statusbar: Turns the always-visible \"Status\" widget (the 'alertbar' layout token, one row tall in the default layout) from an ALERT-only line into a full status bar: clock, uptime, battery percentage/charging state, the CPUMetrics.Throttled flag in red when true, and any currently-firing alert rules in red.\n"+
+			"--record: Record sampled metrics to a .mtop file for later replay or conversion. Name it .mtop.gz for a gzip-compressed recording.\n"+
+			"--replay: Replay a previously recorded .mtop(.gz) file instead of sampling live, bypassing live sampling entirely. Use left/right arrows to seek, space to pause/resume.\n"+
+			"--speed, --replay-speed: Playback speed multiplier for --replay, e.g. '2x'. Default is 1x.\n"+
+			"--bandwidth: Network/disk rate display. Options are 'bytes' (default), 'bits', or 'auto'. Toggle at runtime with 'u'.\n"+
+			"--unit: Unit base for rate/size scaling. Options are 'si' (default, 1000-based) or 'iec' (1024-based).\n"+
+			"--batch: Run headlessly, sampling the same metrics the UI does and emitting one record per tick to stdout.\n"+
+			"--output: Batch record format, 'json' (default) or 'csv'.\n"+
+			"--samples: Stop --batch after N records. Default is unbounded.\n"+
+			"--duration: Stop --batch after e.g. '30s' or '5m'. Default is unbounded.\n"+
+			"--top-n: Number of top-CPU processes to include per --batch record. Default is 5.\n"+
+			"--fields: Comma-separated field list to narrow --batch output, e.g. 'timestamp,cpu_percent,package_watts'.\n\n"+
+			"Alerting: threshold rules read from ~/.mactop/thresholds.toml, ~/.mactop/thresholds.yaml, and/or ~/.config/mactop/alerts.yaml (the latter two are a flat 'rules:' list of name/expr entries, e.g. expr: \"soc_temp > 95 for 30s\"). Recognized metrics: cpu, soc_temp, mem_used, swap_used, power_total, thermal_state, disk_free, disk_read_kbps, disk_write_kbps, proc_cpu, proc_rss. Firings are logged as text to ~/.mactop/mactop.log, as JSON lines to ~/.mactop/events.log, shown via 'a', and summarized in a red status line. A rule's 'for' delays firing and 'resolve_after' delays resolving, to avoid flapping; notify selects 'desktop', 'webhook:<url>', or 'command:<shell command>' (alert fields passed as ALERT_* env vars) for additional sinks.\n\n"+
+			"--history: Keep a rolling on-disk history under ~/.mactop/history/ for this long, e.g. '24h'. Served as GET /history.json?since=<RFC3339> alongside --prometheus; without this flag the endpoint still answers from an in-memory ring buffer of the last hour.\n\n"+
+			"--metrics-config: Path to a JSON config fanning each sample out to pluggable sinks (stdout, InfluxDB line protocol, a Unix socket, Prometheus, StatsD/DogStatsD) with per-metric exclude/rename/add_tags/del_tags rules. See metrics/router.\n\n"+
+			"--statsd-addr: host:port of a StatsD/DogStatsD UDP listener; shorthand for a --metrics-config with a single \"statsd\" sink, for people who just want every sample forwarded with no rules.\n\n"+
+			"API: --prometheus also exposes GET /api/v1/snapshot (latest merged CPU/GPU/SoC/net/disk/process JSON), GET /api/v1/stream (the same snapshot pushed as Server-Sent Events once per tick), and GET /api/v1/processes?sort=cpu&limit=20. --api-socket /path/to.sock binds the same endpoints to a Unix domain socket for local-only consumers (menu-bar apps, Raycast extensions) instead of or alongside the TCP port. --api-token requires an 'Authorization: Bearer <token>' header on the TCP listener; the Unix socket is never token-gated since its reach is already limited to local processes.\n\n"+
+			"--attribute-pid, --attribute-cmd: Apportion the CPU domain's joules to one process (by PID, or a command regexp) by its share of total CPU%% each tick, exposed as mactop_process_energy_joules_total and a running total in the power panel footer. GPU/ANE energy can't be attributed this way and is left unattributed.\n\n"+
+			"--process-cpu-mode: 'normalized' (default) divides each process's ps aux %%CPU by the core count so the column tops out around 100%%; 'raw' shows ps aux's own uncapped figure, so a process pegging 4 cores reads ~400%%, matching top/node_exporter. Persisted in config.json as process_cpu_mode.\n\n"+
+			"--process-source: 'ps' (default) shells out to `ps aux`; 'gopsutil' uses github.com/shirou/gopsutil/v4/process instead, for environments without a ps binary (e.g. a minimal --exporter-only container image). Doesn't affect CPU/power sampling, which always needs Mach APIs.\n\n"+
+			"- Press 'g' to toggle grouping the process list by launchd job label (from `launchctl list`, falling back to the bare command for processes launchctl doesn't know about) or plain command name, rolling up CPU%%/MEM%%/VIRT/RES/POWER/ENERGY per group. Press 'G' to cycle the grouping key between 'launchd-label', 'command', and 'none'. True responsible-process (XPC/helper) grouping would need the private responsibility_get_pid_responsible_for_pid API, which isn't in the public SDK headers this build links against, so it's not offered as a key.\n\n"+
+			"- Press 'b' to toggle the per-core CPU widget between 'bar' (one block-character bar per core) and 'braille' (a scrolling usage-history line at 2x the horizontal resolution, colored by the highest usage in its visible window). See --cpu-graph-mode.\n\n"+
+			"--lang: UI locale, e.g. 'de_DE' or 'zh_CN'. Defaults to $LANG, falling back to en_US for any untranslated string.\n"+
+			"--dump-strings: Print every translatable string as an en_US TOML template, for translators.\n\n"+
+			"Subcommands:\n"+
+			"mactop convert --in path.mtop --out out.csv|out.json --format csv|chrome-trace\n"+
+			"mactop summarize --in path.mtop: Print min/max/avg/p95 for every tracked metric in a recording.\n\n"+
+			"--layout: Select a built-in layout preset ('default', 'minimal', 'battery', 'power', 'procs') or a [name] section from ~/.config/mactop/layout.conf or ~/.mactop/layout.conf. Press 'l' to cycle layouts. 'power' shows a top-N per-process ENERGY bar chart, see --attribute-pid.\n"+
+			"--colorscheme: A built-in scheme ('default', 'default-dark', 'solarized', 'monokai', 'nord', 'auto-light'), 'auto' to pick default-dark/auto-light by querying the terminal's real background color over OSC 11, a name found as ~/.mactop/colorschemes/<name>.json, or a literal path to a colorscheme JSON file. Defaults to ~/.config/mactop/colorscheme.json if present. Fields beyond the original per-widget colors: border, title, selected_fg/selected_bg, secondary_text, bracket, cpu_bar_low/mid/high (CPUCoreWidget's usage-threshold colors). Any field accepts a colorMap name or a bare xterm-256 palette index string, e.g. \"214\" - termui/v3 has no truecolor renderer, so that 256-color table is the ceiling.\n\n"+
 			"Version: %s",
 		prometheusStatus,
+		adaptiveStatus,
 		version,
 	)
 	stderrLogger.Printf("Model: %s\nE-Core Count: %d\nP-Core Count: %d\nGPU Core Count: %s", modelName, eCoreCount, pCoreCount, gpuCoreCount)
 
 	processList = w.NewList()
-	processList.Title = "Process List"
+	processList.Title = tr("widget.process_list.title")
 	processList.TextStyle = ui.NewStyle(ui.ColorGreen)
 	processList.WrapText = false
 	processList.SelectedRowStyle = ui.NewStyle(ui.ColorBlack, ui.ColorGreen)
@@ -533,7 +845,13 @@ func setupUI() {
 	cpuGauge, gpuGauge, memoryGauge, aneGauge = gauges[0], gauges[1], gauges[2], gauges[3]
 
 	PowerChart, NetworkInfo = w.NewParagraph(), w.NewParagraph()
-	PowerChart.Title, NetworkInfo.Title = "Power Usage", "Network & Disk Info"
+	PowerChart.Title, NetworkInfo.Title = tr("widget.power.title"), tr("widget.network.title")
+
+	cpuTimeWidget = w.NewParagraph()
+	cpuTimeWidget.Title = "CPU Time"
+
+	systemStatsWidget = w.NewParagraph()
+	systemStatsWidget.Title = "System Stats"
 
 	termWidth, _ := ui.TerminalDimensions()
 	numPoints := (termWidth / 2) / 2
@@ -570,88 +888,102 @@ func setupUI() {
 		eCoreCount,
 		pCoreCount,
 	)
+
+	if !noBattery {
+		if batt, err := getBatteryMetrics(); err == nil && batt.Present {
+			batteryPresent = true
+			batteryGauge = w.NewGauge()
+			batteryGauge.Title = "Battery"
+			batteryGauge.Percent = batt.PercentCharge
+			batteryGauge.BarColor = ui.ColorGreen
+
+			batterySparkline = w.NewSparkline()
+			batterySparkline.LineColor = ui.ColorGreen
+			batterySparkline.MaxHeight = 100
+			batterySparkline.Data = batteryValues
+			batterySparklineGroup = w.NewSparklineGroup(batterySparkline)
+			batterySparklineGroup.Title = "Power Flow (+charging/-discharging)"
+		}
+	}
 }
 
-func setupGrid() {
-	grid = ui.NewGrid()
-
-	grid.Set(
-		ui.NewRow(1.0/4,
-			ui.NewCol(1.0/2, cpuGauge),
-			ui.NewCol(1.0/2, gpuGauge),
-		),
-		ui.NewRow(2.0/4,
-			ui.NewCol(1.0/2,
-				ui.NewRow(1.0/2, aneGauge),
-				ui.NewRow(1.0/2,
-					ui.NewCol(1.0/2, PowerChart),
-					ui.NewCol(1.0/2, sparklineGroup),
-				),
-			),
-			ui.NewCol(1.0/2,
-				ui.NewRow(1.0/2, memoryGauge),
-				ui.NewRow(1.0/2,
-					ui.NewCol(1.0/3, modelText),
-					ui.NewCol(2.0/3, NetworkInfo),
-				),
-			),
-		),
-		ui.NewRow(1.0/4,
-			ui.NewCol(1.0, processList),
-		),
-	)
+// registerWidgets populates widgetRegistry with every named widget a layout
+// DSL spec can reference. Called once setupUI has built the widgets.
+func registerWidgets() {
+	registerWidget("cpu", cpuGauge)
+	registerWidget("gpu", gpuGauge)
+	registerWidget("ane", aneGauge)
+	registerWidget("mem", memoryGauge)
+	registerWidget("memory", memoryGauge) // alias for layout.conf authors coming from gotop-style names
+	registerWidget("power", PowerChart)
+	registerWidget("net", NetworkInfo)
+	registerWidget("network", NetworkInfo)
+	registerWidget("disk", NetworkInfo) // disk I/O and net share the one panel; both names route here
+	registerWidget("cputime", cpuTimeWidget)
+	registerWidget("sysstats", systemStatsWidget)
+	registerWidget("procs", processList)
+	registerWidget("cores", cpuCoreWidget)
+	registerWidget("sparkline", powerHistoryWidget())
+	registerWidget("model", modelText)
+	registerWidget("help", helpText)
+	registerWidget("energytop", energyTopWidget)
+	alertsWidget.Title = tr("widget.alerts.title")
+	registerWidget("alerts", alertsWidget)
+	registerWidget("alertbar", alertBar)
+	if batteryPresent {
+		registerWidget("battery", batteryGauge)
+		registerWidget("batterysparkline", batterySparklineGroup)
+	}
+
+	// Aliases matching the Go variable names themselves, for layout.conf
+	// authors who'd rather reference widgets the way the source does than
+	// learn the short gotop-style names above.
+	registerWidget("cpuGauge", cpuGauge)
+	registerWidget("gpuGauge", gpuGauge)
+	registerWidget("memoryGauge", memoryGauge)
+	registerWidget("aneGauge", aneGauge)
+	registerWidget("cpuCoreWidget", cpuCoreWidget)
+	registerWidget("modelText", modelText)
+	registerWidget("NetworkInfo", NetworkInfo)
+	registerWidget("PowerChart", PowerChart)
+	registerWidget("processList", processList)
+	registerWidget("sparklineGroup", sparklineGroup)
+	registerWidget("gpuSparklineGroup", gpuSparklineGroup)
 }
 
-func switchGridLayout() {
-	if currentGridLayout == "default" {
-		newGrid := ui.NewGrid()
-		newGrid.Set(
-			ui.NewRow(1.0/2, // This row now takes half the height of the grid
-				ui.NewCol(1.0/2, cpuCoreWidget), ui.NewCol(1.0/2, ui.NewRow(1.0/2, gpuGauge), ui.NewCol(1.0, ui.NewRow(1.0, memoryGauge))), // ui.NewCol(1.0/2, ui.NewRow(1.0, ProcessInfo)), // ProcessInfo spans this entire column
-			),
-			ui.NewRow(1.0/4,
-				ui.NewCol(1.0/6, modelText), ui.NewCol(1.0/3, NetworkInfo), ui.NewCol(1.0/4, PowerChart), ui.NewCol(1.0/4, sparklineGroup),
-			),
-			ui.NewRow(1.0/4,
-				ui.NewCol(1.0, processList),
-			),
-		)
-		termWidth, termHeight := ui.TerminalDimensions()
-		newGrid.SetRect(0, 0, termWidth, termHeight)
-		grid = newGrid
-		currentGridLayout = "alternative"
-	} else {
-		newGrid := ui.NewGrid()
-		newGrid.Set(
-			ui.NewRow(1.0/4,
-				ui.NewCol(1.0/2, cpuGauge),
-				ui.NewCol(1.0/2, aneGauge),
-			),
-			ui.NewRow(2.0/4,
-				ui.NewCol(1.0/2,
-					ui.NewRow(1.0/2, gpuGauge),
-					ui.NewRow(1.0/2,
-						ui.NewCol(1.0/2, PowerChart),
-						ui.NewCol(1.0/2, sparklineGroup),
-					),
-				),
-				ui.NewCol(1.0/2,
-					ui.NewRow(1.0/2, memoryGauge),
-					ui.NewRow(1.0/2,
-						ui.NewCol(1.0/3, modelText),
-						ui.NewCol(2.0/3, NetworkInfo),
-					),
-				),
-			),
-			ui.NewRow(1.0/4,
-				ui.NewCol(1.0, processList),
-			),
-		)
-		termWidth, termHeight := ui.TerminalDimensions()
-		newGrid.SetRect(0, 0, termWidth, termHeight)
-		grid = newGrid
-		currentGridLayout = "default"
+// applyLayoutPreset rebuilds the grid from the named preset (or layout.conf,
+// if present), and records it as the active preset for cycling and for
+// restoring the grid when the help menu is closed.
+func applyLayoutPreset(name string) {
+	spec := resolveLayoutSpec(name)
+	newGrid, err := parseLayoutDSL(spec)
+	if err != nil {
+		stderrLogger.Printf("Failed to apply layout %q: %v\n", name, err)
+		return
+	}
+	termWidth, termHeight := ui.TerminalDimensions()
+	newGrid.SetRect(0, 0, termWidth, termHeight)
+	grid = newGrid
+	currentLayoutPreset = name
+}
+
+// cycleLayoutPreset advances to the next layout, bound to the 'l' key: the
+// next named section in a user's layout.conf if one defines more than one,
+// otherwise the next built-in preset in layoutPresetOrder.
+func cycleLayoutPreset() {
+	order := append(append([]string{}, layoutPresetOrder...), pluginLayoutPresetOrder...)
+	if _, fileOrder, ok := loadLayoutFile(); ok && len(fileOrder) > 1 {
+		order = fileOrder
+	}
+	idx := 0
+	for i, name := range order {
+		if name == currentLayoutPreset {
+			idx = i
+			break
+		}
 	}
+	next := order[(idx+1)%len(order)]
+	applyLayoutPreset(next)
 }
 
 func toggleHelpMenu() {
@@ -671,11 +1003,7 @@ func toggleHelpMenu() {
 		newGrid.SetRect(x, y, x+helpTextGridWidth, y+helpTextGridHeight)
 		grid = newGrid
 	} else {
-		currentGridLayout = map[bool]string{
-			true:  "alternative",
-			false: "default",
-		}[currentGridLayout == "default"]
-		switchGridLayout()
+		applyLayoutPreset(currentLayoutPreset)
 	}
 	ui.Clear()
 	ui.Render(grid)
@@ -820,8 +1148,119 @@ func truncateWithEllipsis(s string, maxLen int) string {
 	return s[:maxLen-3] + "..."
 }
 
+// sampleProcessEnergyImpact reads macOS's own per-process "energy impact"
+// score (the same number Activity Monitor's Energy tab shows - roughly 0-20+
+// per process, a unitless weight, not watts) via `top -l 1 -stats pid,power`,
+// keyed by PID. top's pid/power stat set prints a short header block, then a
+// "PID  POWER" column header, then one "<pid> <score>" line per process -
+// far simpler to parse than powermetrics' wide, version-dependent tasks
+// table, which is why that's used here instead of
+// `powermetrics --samplers tasks` for this column. Returns nil if top is
+// missing, the header was never found, or nothing parsed, so callers fall
+// back to attributeProcessPower's plain CPU%% split.
+func sampleProcessEnergyImpact() map[int]float64 {
+	out, err := exec.Command("top", "-l", "1", "-stats", "pid,power").Output()
+	if err != nil {
+		return nil
+	}
+	return parseProcessEnergyImpact(string(out))
+}
+
+// parseProcessEnergyImpact extracts the "PID  POWER" table `top -l 1 -stats
+// pid,power` prints after its header block, keyed by PID. Split out of
+// sampleProcessEnergyImpact so the parsing can be unit-tested without
+// shelling out to top. Returns nil if the header was never found or nothing
+// parsed, so callers fall back to attributeProcessPower's plain CPU%% split.
+func parseProcessEnergyImpact(out string) map[int]float64 {
+	scores := map[int]float64{}
+	headerSeen := false
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if !headerSeen {
+			if len(fields) == 2 && fields[0] == "PID" && fields[1] == "POWER" {
+				headerSeen = true
+			}
+			continue
+		}
+		if len(fields) != 2 {
+			continue
+		}
+		pid, err1 := strconv.Atoi(fields[0])
+		score, err2 := strconv.ParseFloat(fields[1], 64)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		scores[pid] = score
+	}
+	if !headerSeen || len(scores) == 0 {
+		return nil
+	}
+	return scores
+}
+
+// attributeProcessPower estimates each process's share of lastPackageWatts.
+// When `top -l 1 -stats pid,power` is available, the split follows each
+// process's real per-process energy-impact score; otherwise it falls back to
+// a proportional split by CPU%% across all processes, the one number mactop
+// always measures accurately on this code path (ps aux gives no per-process
+// GPU/ANE breakdown). Either way PowerWatts remains an estimate, not a
+// measurement - there is no per-process wattage API on macOS.
+func attributeProcessPower(processes []ProcessMetrics) {
+	if lastPackageWatts <= 0 {
+		return
+	}
+
+	if scores := sampleProcessEnergyImpact(); scores != nil {
+		var totalScore float64
+		for _, p := range processes {
+			totalScore += scores[p.PID]
+		}
+		if totalScore > 0 {
+			for i := range processes {
+				processes[i].PowerWatts = lastPackageWatts * (scores[processes[i].PID] / totalScore)
+			}
+			publishProcessPower(processes)
+			return
+		}
+	}
+
+	var totalCPU float64
+	for _, p := range processes {
+		totalCPU += p.CPU
+	}
+	if totalCPU <= 0 {
+		return
+	}
+	for i := range processes {
+		processes[i].PowerWatts = lastPackageWatts * (processes[i].CPU / totalCPU)
+	}
+	publishProcessPower(processes)
+}
+
+// publishProcessPower exports the top processPowerTopN processes by
+// PowerWatts as mactop_process_power_watts, resetting the vec first since the
+// set of top processes (and thus the pid/command label pairs) changes tick to
+// tick.
+func publishProcessPower(processes []ProcessMetrics) {
+	top := append([]ProcessMetrics(nil), processes...)
+	sort.Slice(top, func(i, j int) bool { return top[i].PowerWatts > top[j].PowerWatts })
+	if len(top) > processPowerTopN {
+		top = top[:processPowerTopN]
+	}
+	processPowerWatts.Reset()
+	for _, p := range top {
+		processPowerWatts.With(prometheus.Labels{
+			"pid":     strconv.Itoa(p.PID),
+			"command": p.Command,
+		}).Set(p.PowerWatts)
+	}
+}
+
 func updateProcessList() {
 	processes := getProcessList()
+	attributeProcessPower(processes)
+	accumulateProcessEnergy(processes)
+	processes = groupProcesses(processes)
 	themeColor := processList.TextStyle.Fg
 	themeColorStr := "white" // Default color in case theme color isn't recognized
 	switch themeColor {
@@ -844,14 +1283,16 @@ func updateProcessList() {
 	minWidth := 40           // Set a minimum width to prevent crashes
 	availableWidth := max(termWidth-2, minWidth)
 	maxWidths := map[string]int{
-		"PID":  5,  // Minimum for PID
-		"USER": 12, // Fixed maximum width for USER
-		"VIRT": 6,  // For memory format
-		"RES":  6,  // For memory format
-		"CPU":  6,  // For "XX.X%"
-		"MEM":  5,  // For "X.X%"
-		"TIME": 8,  // For time format
-		"CMD":  13, // Minimum for command
+		"PID":    5,  // Minimum for PID
+		"USER":   12, // Fixed maximum width for USER
+		"VIRT":   6,  // For memory format
+		"RES":    6,  // For memory format
+		"CPU":    6,  // For "XX.X%"
+		"MEM":    5,  // For "X.X%"
+		"POWER":  6,  // For "X.XXW"
+		"ENERGY": 7,  // For "X.XkJ"
+		"TIME":   8,  // For time format
+		"CMD":    13, // Minimum for command
 	}
 	usedWidth := 0
 	for col, width := range maxWidths {
@@ -872,7 +1313,7 @@ func updateProcessList() {
 			format = fmt.Sprintf("%%-%ds", width) // Left-align
 		case "VIRT", "RES":
 			format = fmt.Sprintf("%%%ds", width) // Right-align
-		case "CPU", "MEM":
+		case "CPU", "MEM", "POWER", "ENERGY":
 			format = fmt.Sprintf("%%%ds", width) // Right-align
 		case "TIME":
 			format = fmt.Sprintf("%%%ds", width) // Right-align
@@ -912,6 +1353,10 @@ func updateProcessList() {
 			result = processes[i].CPU > processes[j].CPU
 		case "MEM":
 			result = processes[i].Memory > processes[j].Memory
+		case "POWER":
+			result = processes[i].PowerWatts > processes[j].PowerWatts
+		case "ENERGY":
+			result = processes[i].EnergyMJ > processes[j].EnergyMJ
 		case "TIME":
 			iTime := parseTimeString(processes[i].Time)
 			jTime := parseTimeString(processes[j].Time)
@@ -938,20 +1383,54 @@ func updateProcessList() {
 		resStr := formatResMemorySize(p.RSS)
 		username := truncateWithEllipsis(p.User, maxWidths["USER"])
 
-		items[i+1] = fmt.Sprintf("%*d %-*s %*s %*s %*.1f%% %*.1f%% %*s %-s",
+		powerStr := fmt.Sprintf("%.2fW", p.PowerWatts)
+		energyStr := formatEnergy(p.EnergyMJ)
+
+		items[i+1] = fmt.Sprintf("%*d %-*s %*s %*s %*.1f%% %*.1f%% %*s %*s %*s %-s",
 			maxWidths["PID"], p.PID,
 			maxWidths["USER"], username,
 			maxWidths["VIRT"], virtStr,
 			maxWidths["RES"], resStr,
 			maxWidths["CPU"]-1, p.CPU, // -1 for % symbol
 			maxWidths["MEM"]-1, p.Memory, // -1 for % symbol
+			maxWidths["POWER"], powerStr,
+			maxWidths["ENERGY"], energyStr,
 			maxWidths["TIME"], timeStr,
 			truncateWithEllipsis(p.Command, maxWidths["CMD"]),
 		)
 	}
 
-	processList.Title = "Process List (↑/↓ scroll, ←/→ select column, Enter/Space to sort)"
+	processList.Title = "Process List (↑/↓ scroll, ←/→ select column, Enter/Space to sort, t/K to term/kill, g/G to group)"
+	if processGroupingEnabled && currentProcessGroupKey() != "none" {
+		processList.Title = fmt.Sprintf("Process List - grouped by %s (g to ungroup, G to cycle key)", currentProcessGroupKey())
+	}
 	processList.Rows = items
+	sortedProcesses = processes
+}
+
+// selectedPID returns the PID backing the process list's currently
+// selected row, or 0 if nothing is selected (row 0 is the header, so row i
+// maps to sortedProcesses[i-1]).
+func selectedPID() int {
+	row := processList.SelectedRow
+	idx := row - 1
+	if idx < 0 || idx >= len(sortedProcesses) {
+		return 0
+	}
+	return sortedProcesses[idx].PID
+}
+
+// signalSelectedProcess sends sig to the PID under the process list's
+// selection, the same "act on what's highlighted" pattern the column/sort
+// keys already use. A no-op if nothing is selected.
+func signalSelectedProcess(sig syscall.Signal) {
+	pid := selectedPID()
+	if pid == 0 {
+		return
+	}
+	if err := syscall.Kill(pid, sig); err != nil {
+		stderrLogger.Printf("Failed to signal PID %d with %v: %v\n", pid, sig, err)
+	}
 }
 
 func handleProcessListEvents(e ui.Event) {
@@ -965,16 +1444,36 @@ func handleProcessListEvents(e ui.Event) {
 			processList.SelectedRow++
 		}
 	case "<Left>":
-		if selectedColumn > 0 {
+		if replayPath != "" {
+			select {
+			case replaySeekChan <- -1:
+			default:
+			}
+		} else if selectedColumn > 0 {
 			selectedColumn--
 			updateProcessList()
 		}
 	case "<Right>":
-		if selectedColumn < len(columns)-1 {
+		if replayPath != "" {
+			select {
+			case replaySeekChan <- 1:
+			default:
+			}
+		} else if selectedColumn < len(columns)-1 {
 			selectedColumn++
 			updateProcessList()
 		}
-	case "<Enter>", "<Space>":
+	case "<Space>":
+		if replayPath != "" {
+			select {
+			case replayPauseChan <- struct{}{}:
+			default:
+			}
+			break
+		}
+		sortReverse = !sortReverse
+		updateProcessList()
+	case "<Enter>":
 		sortReverse = !sortReverse
 		updateProcessList()
 	}
@@ -1032,10 +1531,30 @@ func main() {
 		err                   error
 		setColor, setInterval bool
 	)
+	if len(os.Args) > 1 && os.Args[1] == "convert" {
+		runConvert(os.Args[2:])
+		os.Exit(0)
+	}
+	if len(os.Args) > 1 && os.Args[1] == "summarize" {
+		runSummarize(os.Args[2:])
+		os.Exit(0)
+	}
+
+	for i, arg := range os.Args {
+		if arg == "--lang" && i+1 < len(os.Args) {
+			langFlag = os.Args[i+1]
+		}
+	}
+	loadTranslations()
+	if len(os.Args) > 1 && os.Args[1] == "--dump-strings" {
+		runDumpStrings()
+		os.Exit(0)
+	}
+
 	for i := 1; i < len(os.Args); i++ {
 		switch os.Args[i] {
 		case "--help", "-h":
-			fmt.Print("Usage: mactop [--help] [--version] [--interval] [--color]\n--help: Show this help message\n--version: Show the version of mactop\n--interval: Set the update interval in milliseconds. Default is 1000.\n--color: Set the UI color. Default is none. Options are 'green', 'red', 'blue', 'cyan', 'magenta', 'yellow', and 'white'. (-c green)\n\nFor more information, see https://github.com/context-labs/mactop written by Carsen Klock.\n")
+			fmt.Print("Usage: mactop [--help] [--version] [--interval] [--color] [--no-battery]\n--help: Show this help message\n--version: Show the version of mactop\n--interval: Set the update interval in milliseconds. Default is 1000.\n--color: Set the UI color. Default is none. Options are 'green', 'red', 'blue', 'cyan', 'magenta', 'yellow', and 'white'. (-c green)\n--no-battery: Disable the battery widget, for desktops (Mac Studio/Mini) with no battery.\n\nFor more information, see https://github.com/context-labs/mactop written by Carsen Klock.\n")
 			os.Exit(0)
 		case "--version", "-v":
 			fmt.Println("mactop version:", version)
@@ -1073,65 +1592,450 @@ func main() {
 				fmt.Println("Error: --prometheus flag requires a port number")
 				os.Exit(1)
 			}
-		case "--interval", "-i":
+		case "--no-battery":
+			noBattery = true
+		case "--exporter":
 			if i+1 < len(os.Args) {
-				interval, err = strconv.Atoi(os.Args[i+1])
+				mode, err := parseExporterFlag(os.Args[i+1])
 				if err != nil {
-					fmt.Println("Invalid interval:", err)
+					fmt.Println("Error:", err)
 					os.Exit(1)
 				}
-				setInterval = true
+				exporterFlag = mode
 				i++
 			} else {
-				fmt.Println("Error: --interval flag requires an interval value")
+				fmt.Println("Error: --exporter flag requires a value (prometheus, otlp, or both)")
 				os.Exit(1)
 			}
-		}
-	}
-
-	logfile, err := setupLogfile()
-	if err != nil {
-		stderrLogger.Fatalf("failed to setup log file: %v", err)
-	}
-	defer logfile.Close()
-
-	if err := ui.Init(); err != nil {
-		stderrLogger.Fatalf("failed to initialize termui: %v", err)
-	}
-	defer ui.Close()
-	StderrToLogfile(logfile)
-
-	ttyFile, err = os.Open("/dev/tty")
-	if err != nil {
-		ui.Close()
-		stderrLogger.Fatalf("failed to open /dev/tty: %v", err)
-	}
-	defer ttyFile.Close()
-
-	if prometheusPort != "" {
-		startPrometheusServer(prometheusPort)
-		stderrLogger.Printf("Prometheus metrics available at http://localhost:%s/metrics\n", prometheusPort)
-	}
-	if setColor {
-		var color ui.Color
-		switch colorName {
-		case "green":
-			color = ui.ColorGreen
-		case "red":
-			color = ui.ColorRed
-		case "blue":
-			color = ui.ColorBlue
-		case "cyan":
-			color = ui.ColorCyan
-		case "magenta":
-			color = ui.ColorMagenta
-		case "yellow":
-			color = ui.ColorYellow
-		case "white":
-			color = ui.ColorWhite
-		default:
-			stderrLogger.Printf("Unsupported color: %s. Using default color.\n", colorName)
-			color = ui.ColorWhite
+		case "--listen":
+			if i+1 < len(os.Args) {
+				listenAddr = os.Args[i+1]
+				i++
+			}
+		case "--otlp-endpoint":
+			if i+1 < len(os.Args) {
+				otlpEndpoint = os.Args[i+1]
+				i++
+			}
+		case "--otlp-interval", "--export-interval": // --export-interval is an alias, for parity with --otlp-endpoint/--otlp-headers naming
+			if i+1 < len(os.Args) {
+				if d, err := time.ParseDuration(os.Args[i+1]); err == nil {
+					otlpInterval = d
+				}
+				i++
+			}
+		case "--otlp-headers":
+			if i+1 < len(os.Args) {
+				otlpHeaders = parseOTLPHeaders(os.Args[i+1])
+				i++
+			} else {
+				fmt.Println("Error: --otlp-headers flag requires a value, e.g. 'Authorization=Bearer <token>'")
+				os.Exit(1)
+			}
+		case "--exporter-only":
+			exporterOnly = true
+		case "--statusbar":
+			statusBarEnabled = true
+		case "--headless-format":
+			if i+1 < len(os.Args) {
+				headlessFormat = strings.ToLower(os.Args[i+1])
+				if headlessFormat == "influx" {
+					headlessFormat = "influx-lineproto"
+				}
+				switch headlessFormat {
+				case "json", "openmetrics", "influx-lineproto":
+				default:
+					fmt.Println("Error: --headless-format must be json, openmetrics, or influx-lineproto")
+					os.Exit(1)
+				}
+				i++
+			} else {
+				fmt.Println("Error: --headless-format flag requires a value")
+				os.Exit(1)
+			}
+		case "--record":
+			if i+1 < len(os.Args) {
+				recordPath = os.Args[i+1]
+				i++
+			} else {
+				fmt.Println("Error: --record flag requires a file path")
+				os.Exit(1)
+			}
+		case "--replay":
+			if i+1 < len(os.Args) {
+				replayPath = os.Args[i+1]
+				i++
+			} else {
+				fmt.Println("Error: --replay flag requires a file path")
+				os.Exit(1)
+			}
+		case "--bandwidth":
+			if i+1 < len(os.Args) {
+				mode, err := parseBandwidthFlag(os.Args[i+1])
+				if err != nil {
+					fmt.Println("Error:", err)
+					os.Exit(1)
+				}
+				bandwidthFlag = mode
+				i++
+			} else {
+				fmt.Println("Error: --bandwidth flag requires a value")
+				os.Exit(1)
+			}
+		case "--unit":
+			if i+1 < len(os.Args) {
+				unit, err := parseUnitFlag(os.Args[i+1])
+				if err != nil {
+					fmt.Println("Error:", err)
+					os.Exit(1)
+				}
+				unitFlag = unit
+				i++
+			} else {
+				fmt.Println("Error: --unit flag requires a value")
+				os.Exit(1)
+			}
+		case "--speed", "--replay-speed":
+			if i+1 < len(os.Args) {
+				replaySpeed = parseSpeed(os.Args[i+1])
+				i++
+			}
+		case "--history":
+			if i+1 < len(os.Args) {
+				d, err := time.ParseDuration(os.Args[i+1])
+				if err != nil {
+					fmt.Println("Error: --history requires a duration, e.g. '24h'")
+					os.Exit(1)
+				}
+				historyFlag = d
+				i++
+			} else {
+				fmt.Println("Error: --history flag requires a duration, e.g. '24h'")
+				os.Exit(1)
+			}
+		case "--layout":
+			if i+1 < len(os.Args) {
+				layoutFlag = os.Args[i+1]
+				i++
+			} else {
+				fmt.Println("Error: --layout flag requires a value (default, minimal, battery, power, or procs)")
+				os.Exit(1)
+			}
+		case "--process-cpu-mode":
+			if i+1 < len(os.Args) {
+				mode := strings.ToLower(os.Args[i+1])
+				switch mode {
+				case "normalized", "raw":
+					currentConfig.ProcessCPUMode = mode
+				default:
+					fmt.Println("Error: --process-cpu-mode must be normalized or raw")
+					os.Exit(1)
+				}
+				i++
+			} else {
+				fmt.Println("Error: --process-cpu-mode flag requires a value (normalized or raw)")
+				os.Exit(1)
+			}
+		case "--process-source":
+			if i+1 < len(os.Args) {
+				if err := selectProcessSource(os.Args[i+1]); err != nil {
+					fmt.Printf("Error: %v\n", err)
+					os.Exit(1)
+				}
+				i++
+			} else {
+				fmt.Println("Error: --process-source flag requires a value (ps or gopsutil)")
+				os.Exit(1)
+			}
+		case "--metrics-config":
+			if i+1 < len(os.Args) {
+				metricsConfigFlag = os.Args[i+1]
+				i++
+			} else {
+				fmt.Println("Error: --metrics-config flag requires a path to a JSON config file")
+				os.Exit(1)
+			}
+		case "--statsd-addr":
+			if i+1 < len(os.Args) {
+				statsdAddrFlag = os.Args[i+1]
+				i++
+			} else {
+				fmt.Println("Error: --statsd-addr flag requires a host:port")
+				os.Exit(1)
+			}
+		case "--api-socket":
+			if i+1 < len(os.Args) {
+				apiSocketPath = os.Args[i+1]
+				i++
+			} else {
+				fmt.Println("Error: --api-socket flag requires a path")
+				os.Exit(1)
+			}
+		case "--api-token":
+			if i+1 < len(os.Args) {
+				apiToken = os.Args[i+1]
+				i++
+			} else {
+				fmt.Println("Error: --api-token flag requires a value")
+				os.Exit(1)
+			}
+		case "--cpu-graph-mode":
+			if i+1 < len(os.Args) {
+				switch os.Args[i+1] {
+				case "bar", "braille":
+					cpuGraphMode = os.Args[i+1]
+					i++
+				default:
+					fmt.Println("Error: --cpu-graph-mode flag must be 'bar' or 'braille'")
+					os.Exit(1)
+				}
+			} else {
+				fmt.Println("Error: --cpu-graph-mode flag requires a value (bar or braille)")
+				os.Exit(1)
+			}
+		case "--attribute-pid":
+			if i+1 < len(os.Args) {
+				pid, err := strconv.Atoi(os.Args[i+1])
+				if err != nil {
+					fmt.Println("Error: --attribute-pid requires an integer PID")
+					os.Exit(1)
+				}
+				attributePID = pid
+				i++
+			} else {
+				fmt.Println("Error: --attribute-pid flag requires a PID")
+				os.Exit(1)
+			}
+		case "--attribute-cmd":
+			if i+1 < len(os.Args) {
+				re, err := regexp.Compile(os.Args[i+1])
+				if err != nil {
+					fmt.Printf("Error: --attribute-cmd is not a valid regexp: %v\n", err)
+					os.Exit(1)
+				}
+				attributeCmdRe = re
+				i++
+			} else {
+				fmt.Println("Error: --attribute-cmd flag requires a regexp")
+				os.Exit(1)
+			}
+		case "--lang":
+			if i+1 < len(os.Args) {
+				i++ // already consumed by the pre-scan above; just skip its value here
+			}
+		case "--dump-strings":
+			// handled by the pre-scan above, before flag parsing
+		case "--colorscheme":
+			if i+1 < len(os.Args) {
+				colorSchemePath = os.Args[i+1]
+				i++
+			} else {
+				fmt.Println("Error: --colorscheme flag requires a file path")
+				os.Exit(1)
+			}
+		case "--render":
+			if i+1 < len(os.Args) {
+				switch os.Args[i+1] {
+				case "braille", "blocks":
+					renderMode = os.Args[i+1]
+				default:
+					fmt.Println("Error: --render flag must be 'braille' or 'blocks'")
+					os.Exit(1)
+				}
+				i++
+			} else {
+				fmt.Println("Error: --render flag requires a value (braille or blocks)")
+				os.Exit(1)
+			}
+		case "--interval", "-i":
+			if i+1 < len(os.Args) {
+				interval, err = strconv.Atoi(os.Args[i+1])
+				if err != nil {
+					fmt.Println("Invalid interval:", err)
+					os.Exit(1)
+				}
+				setInterval = true
+				i++
+			} else {
+				fmt.Println("Error: --interval flag requires an interval value")
+				os.Exit(1)
+			}
+		case "--adaptive":
+			adaptiveFlag = true
+			if i+1 < len(os.Args) && (os.Args[i+1] == "on" || os.Args[i+1] == "off") {
+				adaptiveFlag = os.Args[i+1] == "on"
+				i++
+			}
+		case "--min-interval":
+			if i+1 < len(os.Args) {
+				minIntervalMS, err = strconv.Atoi(os.Args[i+1])
+				if err != nil {
+					fmt.Println("Invalid --min-interval:", err)
+					os.Exit(1)
+				}
+				i++
+			} else {
+				fmt.Println("Error: --min-interval flag requires a millisecond value")
+				os.Exit(1)
+			}
+		case "--max-interval":
+			if i+1 < len(os.Args) {
+				maxIntervalMS, err = strconv.Atoi(os.Args[i+1])
+				if err != nil {
+					fmt.Println("Invalid --max-interval:", err)
+					os.Exit(1)
+				}
+				i++
+			} else {
+				fmt.Println("Error: --max-interval flag requires a millisecond value")
+				os.Exit(1)
+			}
+		case "--net-iface":
+			if i+1 < len(os.Args) {
+				netIfaceFilter = strings.Split(os.Args[i+1], ",")
+				i++
+			} else {
+				fmt.Println("Error: --net-iface flag requires a comma-separated interface list")
+				os.Exit(1)
+			}
+		case "--disk-device":
+			if i+1 < len(os.Args) {
+				diskDeviceFilter = strings.Split(os.Args[i+1], ",")
+				i++
+			} else {
+				fmt.Println("Error: --disk-device flag requires a comma-separated device list")
+				os.Exit(1)
+			}
+		case "--batch":
+			batchFlag = true
+		case "--output":
+			if i+1 < len(os.Args) {
+				batchOutput = os.Args[i+1]
+				if batchOutput != "json" && batchOutput != "csv" {
+					fmt.Println("Error: --output must be json or csv")
+					os.Exit(1)
+				}
+				i++
+			} else {
+				fmt.Println("Error: --output flag requires a value (json or csv)")
+				os.Exit(1)
+			}
+		case "--samples":
+			if i+1 < len(os.Args) {
+				n, err := strconv.Atoi(os.Args[i+1])
+				if err != nil {
+					fmt.Println("Invalid --samples value:", err)
+					os.Exit(1)
+				}
+				batchSamples = n
+				i++
+			} else {
+				fmt.Println("Error: --samples flag requires a count")
+				os.Exit(1)
+			}
+		case "--duration":
+			if i+1 < len(os.Args) {
+				d, err := time.ParseDuration(os.Args[i+1])
+				if err != nil {
+					fmt.Println("Invalid --duration value:", err)
+					os.Exit(1)
+				}
+				batchDuration = d
+				i++
+			} else {
+				fmt.Println("Error: --duration flag requires a value (e.g. 30s)")
+				os.Exit(1)
+			}
+		case "--top-n":
+			if i+1 < len(os.Args) {
+				n, err := strconv.Atoi(os.Args[i+1])
+				if err != nil {
+					fmt.Println("Invalid --top-n value:", err)
+					os.Exit(1)
+				}
+				batchTopN = n
+				i++
+			} else {
+				fmt.Println("Error: --top-n flag requires a count")
+				os.Exit(1)
+			}
+		case "--fields":
+			if i+1 < len(os.Args) {
+				batchFieldsArg = os.Args[i+1]
+				i++
+			} else {
+				fmt.Println("Error: --fields flag requires a comma-separated list")
+				os.Exit(1)
+			}
+		}
+	}
+
+	logfile, err := setupLogfile()
+	if err != nil {
+		stderrLogger.Fatalf("failed to setup log file: %v", err)
+	}
+	defer logfile.Close()
+
+	if batchFlag {
+		StderrToLogfile(logfile)
+		runBatch()
+		return
+	}
+
+	if exporterOnly {
+		StderrToLogfile(logfile)
+		promRegistry := startPromServices()
+		startMetricsRouter(metricsConfigFlag, promRegistry)
+		startExporters(promRegistry)
+		runHeadless(0)
+		return
+	}
+
+	var autoColorScheme ColorScheme
+	if colorSchemePath == "auto" {
+		// Must happen before ui.Init() claims the tty for termbox-go's
+		// background input reader; see resolveAutoColorScheme's doc comment.
+		autoColorScheme = resolveAutoColorScheme()
+	}
+
+	if err := ui.Init(); err != nil {
+		stderrLogger.Fatalf("failed to initialize termui: %v", err)
+	}
+	defer ui.Close()
+	StderrToLogfile(logfile)
+
+	ttyFile, err = os.Open("/dev/tty")
+	if err != nil {
+		ui.Close()
+		stderrLogger.Fatalf("failed to open /dev/tty: %v", err)
+	}
+	defer ttyFile.Close()
+
+	startHistoryStore(historyFlag)
+
+	promRegistry := startPromServices()
+	startMetricsRouter(metricsConfigFlag, promRegistry)
+	startExporters(promRegistry)
+	if setColor {
+		var color ui.Color
+		switch colorName {
+		case "green":
+			color = ui.ColorGreen
+		case "red":
+			color = ui.ColorRed
+		case "blue":
+			color = ui.ColorBlue
+		case "cyan":
+			color = ui.ColorCyan
+		case "magenta":
+			color = ui.ColorMagenta
+		case "yellow":
+			color = ui.ColorYellow
+		case "white":
+			color = ui.ColorWhite
+		default:
+			stderrLogger.Printf("Unsupported color: %s. Using default color.\n", colorName)
+			color = ui.ColorWhite
 		}
 		ui.Theme.Block.Title.Fg, ui.Theme.Block.Border.Fg, ui.Theme.Paragraph.Text.Fg, ui.Theme.Gauge.Label.Fg, ui.Theme.Gauge.Bar = color, color, color, color, color
 		ui.Theme.BarChart.Bars = []ui.Color{color}
@@ -1145,13 +2049,60 @@ func main() {
 	if setInterval {
 		updateInterval = interval
 	}
-	setupGrid()
+	setupAdaptiveSampler()
+	if renderMode == "braille" && autodetectRenderMode() != "braille" {
+		stderrLogger.Printf("Terminal does not appear to support braille well (non-UTF-8 LANG); falling back to --render=blocks\n")
+		renderMode = "blocks"
+	}
+	if renderMode == "braille" {
+		powerBrailleSparkline = NewBrailleSparkline()
+		powerBrailleSparkline.LineColor = ui.ColorGreen
+		powerBrailleSparkline.Data = powerValues
+		powerBrailleSparkline.MaxVal = 8
+		powerBrailleGraph = NewBrailleGraph(powerBrailleSparkline)
+
+		gpuBrailleSparkline = NewBrailleSparkline()
+		gpuBrailleSparkline.LineColor = ui.ColorGreen
+		gpuBrailleSparkline.Data = gpuValues
+		gpuBrailleSparkline.MaxVal = 100
+		gpuBrailleGraph = NewBrailleGraph(gpuBrailleSparkline)
+		gpuBrailleGraph.Title = "GPU Usage History"
+	}
+	if colorSchemePath == "auto" {
+		applyColorScheme(autoColorScheme)
+	} else if scheme, ok := loadColorSchemeFile(colorSchemePath); ok {
+		applyColorScheme(scheme)
+	}
+	registerWidgets()
+	loadPlugins()
+	loadThresholds()
+	startupLayout := layoutFlag
+	if layouts, fileOrder, ok := loadLayoutFile(); ok && len(fileOrder) > 0 {
+		if _, explicit := layouts[layoutFlag]; !explicit {
+			startupLayout = fileOrder[0]
+		}
+	}
+	applyLayoutPreset(startupLayout)
 	termWidth, termHeight := ui.TerminalDimensions()
 	grid.SetRect(0, 0, termWidth, termHeight)
 	cpuMetricsChan := make(chan CPUMetrics, 1)
 	gpuMetricsChan := make(chan GPUMetrics, 1)
 	netdiskMetricsChan := make(chan NetDiskMetrics, 1)
-	go collectMetrics(done, cpuMetricsChan, gpuMetricsChan, netdiskMetricsChan)
+	batteryMetricsChan := make(chan BatteryMetrics, 1)
+	if recordPath != "" {
+		rec, err := NewRecorder(recordPath)
+		if err != nil {
+			stderrLogger.Printf("Failed to start recording: %v\n", err)
+		} else {
+			activeRecorder = rec
+			defer activeRecorder.Close()
+		}
+	}
+	if replayPath != "" {
+		go replayMetrics(done, replayPath, replaySpeed, cpuMetricsChan, gpuMetricsChan, netdiskMetricsChan, batteryMetricsChan, replaySeekChan, replayPauseChan)
+	} else {
+		go collectMetrics(done, cpuMetricsChan, gpuMetricsChan, netdiskMetricsChan, batteryMetricsChan)
+	}
 	go func() {
 		ticker := time.NewTicker(time.Duration(updateInterval) * time.Millisecond)
 		defer ticker.Stop()
@@ -1167,26 +2118,51 @@ func main() {
 			case netdiskMetrics := <-netdiskMetricsChan:
 				updateNetDiskUI(netdiskMetrics)
 				ui.Render(grid)
+			case batteryMetrics := <-batteryMetricsChan:
+				updateBatteryUI(batteryMetrics)
+				ui.Render(grid)
 			case <-ticker.C:
+				select {
+				case pluginTickChan <- time.Now():
+				default:
+				}
 				percentages, err := GetCPUPercentages()
 				if err != nil {
 					stderrLogger.Printf("Error getting CPU percentages: %v\n", err)
 					continue
 				}
 				cpuCoreWidget.UpdateUsage(percentages)
-				var totalUsage float64
+				var totalUsage, peakCore float64
 				for _, usage := range percentages {
 					totalUsage += usage
+					if usage > peakCore {
+						peakCore = usage
+					}
 				}
 				totalUsage /= float64(len(percentages))
 
-				cpuCoreWidget.Title = fmt.Sprintf("mactop - %d Cores (%dE/%dP) %.2f%%",
+				adaptiveIndicator := ""
+				runExpensive := true
+				if adaptiveSamplerState != nil {
+					thermalStr, _ := getThermalStateString()
+					adaptiveSamplerState.updateMultiplier(lastPackageWatts, peakCore, strings.EqualFold(thermalStr, "Nominal"))
+					runExpensive = adaptiveSamplerState.shouldRunUI()
+					if mult := adaptiveSamplerState.currentMultiplier(); mult > 1 {
+						adaptiveIndicator = fmt.Sprintf(" [adaptive %dx, %s]", mult, adaptiveSamplerState.effectiveInterval())
+					}
+				}
+				sampleIntervalGauge.Set(float64(currentSampleIntervalMS()))
+
+				cpuCoreWidget.Title = fmt.Sprintf("mactop - %d Cores (%dE/%dP) %.2f%%%s",
 					cpuCoreWidget.eCoreCount+cpuCoreWidget.pCoreCount,
 					cpuCoreWidget.eCoreCount,
 					cpuCoreWidget.pCoreCount,
 					totalUsage,
+					adaptiveIndicator,
 				)
-				updateProcessList()
+				if runExpensive {
+					updateProcessList()
+				}
 				ui.Render(grid)
 			case <-done:
 				return
@@ -1208,6 +2184,9 @@ func main() {
 	for {
 		select {
 		case key := <-keyboardInput:
+			if adaptiveSamplerState != nil {
+				adaptiveSamplerState.resetToBase()
+			}
 			fakeEvent := ui.Event{Type: ui.KeyboardEvent, ID: key}
 			handleProcessListEvents(fakeEvent)
 			switch key {
@@ -1230,10 +2209,37 @@ func main() {
 				ui.Clear()
 				ui.Render(grid)
 			case "l":
+				ui.Clear()
+				cycleLayoutPreset()
+				ui.Render(grid)
+			case "u":
+				cycleBandwidthMode()
+				ui.Render(grid)
+			case "t":
+				signalSelectedProcess(syscall.SIGTERM)
+			case "K":
+				signalSelectedProcess(syscall.SIGKILL)
+			case "a":
 				termWidth, termHeight := ui.TerminalDimensions()
 				grid.SetRect(0, 0, termWidth, termHeight)
-				ui.Clear()
-				switchGridLayout()
+				toggleAlertsWidget()
+			case "P":
+				for i, c := range columns {
+					if c == "POWER" {
+						selectedColumn = i
+						break
+					}
+				}
+				sortReverse = false
+				updateProcessList()
+			case "g":
+				toggleProcessGrouping()
+				updateProcessList()
+			case "G":
+				cycleProcessGroupKey()
+				updateProcessList()
+			case "b":
+				cycleCPUGraphMode()
 				ui.Render(grid)
 			case "h", "?":
 				termWidth, termHeight := ui.TerminalDimensions()
@@ -1241,6 +2247,11 @@ func main() {
 				ui.Clear()
 				toggleHelpMenu()
 				ui.Render(grid)
+			default:
+				if handler, ok := pluginKeybindings[key]; ok {
+					handler()
+					ui.Render(grid)
+				}
 			}
 		case e := <-uiEvents:
 			if e.ID == "<Resize>" {
@@ -1256,16 +2267,15 @@ func main() {
 	}
 }
 
+// setupLogfile opens the state-log file stdlib log.Printf writes runtime
+// errors to (distinct from alerts.go's own ~/.mactop/mactop.log, which is a
+// pre-existing, separately-managed alert-firing log).
 func setupLogfile() (*os.File, error) {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		homeDir = os.TempDir()
-	}
-	logDir := filepath.Join(homeDir, ".mactop")
+	logDir := StateDir()
 	if err := os.MkdirAll(logDir, 0755); err != nil {
 		return nil, fmt.Errorf("failed to make the log directory: %v", err)
 	}
-	logPath := filepath.Join(logDir, "mactop.log")
+	logPath := filepath.Join(logDir, "errors.log")
 	logfile, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0660)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open log file: %v", err)
@@ -1284,8 +2294,32 @@ func getThermalStateString() (string, bool) {
 	return "Unknown", false
 }
 
+// passesFilter reports whether name should be tracked given a --net-iface or
+// --disk-device allowlist; an empty filter allows everything.
+func passesFilter(name string, filter []string) bool {
+	if len(filter) == 0 {
+		return true
+	}
+	for _, f := range filter {
+		if f == name {
+			return true
+		}
+	}
+	return false
+}
+
+// getNetDiskMetrics samples every network interface (net.IOCounters(true))
+// and every disk device (disk.IOCounters()'s own per-device map) individually
+// against a per-name lastNetStats/lastDiskStats map, filtered by
+// --net-iface/--disk-device, then also rolls the filtered set up into the
+// same aggregate fields this function has always returned so existing
+// callers (updateNetDiskUI, recordHistoryPoint, alert evaluation, the API
+// handlers) keep working unchanged.
 func getNetDiskMetrics() NetDiskMetrics {
-	var metrics NetDiskMetrics
+	metrics := NetDiskMetrics{
+		PerInterface: make(map[string]IfaceRate),
+		PerDevice:    make(map[string]DiskRate),
+	}
 
 	netDiskMutex.Lock()
 	defer netDiskMutex.Unlock()
@@ -1295,41 +2329,51 @@ func getNetDiskMetrics() NetDiskMetrics {
 	if elapsed <= 0 {
 		elapsed = 1
 	}
+	first := lastNetDiskTime.IsZero()
 
-	netStats, err := net.IOCounters(false)
-	if err == nil && len(netStats) > 0 {
-		current := netStats[0]
-		if lastNetDiskTime.IsZero() {
-			lastNetStats = current
-		} else {
-			metrics.InBytesPerSec = float64(current.BytesRecv-lastNetStats.BytesRecv) / elapsed / 1000
-			metrics.OutBytesPerSec = float64(current.BytesSent-lastNetStats.BytesSent) / elapsed / 1000
-			metrics.InPacketsPerSec = float64(current.PacketsRecv-lastNetStats.PacketsRecv) / elapsed
-			metrics.OutPacketsPerSec = float64(current.PacketsSent-lastNetStats.PacketsSent) / elapsed
+	netStats, err := net.IOCounters(true)
+	if err == nil {
+		for _, current := range netStats {
+			if !passesFilter(current.Name, netIfaceFilter) {
+				continue
+			}
+			if prev, ok := lastNetStats[current.Name]; ok && !first {
+				rate := IfaceRate{
+					InBytesPerSec:    float64(current.BytesRecv-prev.BytesRecv) / elapsed / 1000,
+					OutBytesPerSec:   float64(current.BytesSent-prev.BytesSent) / elapsed / 1000,
+					InPacketsPerSec:  float64(current.PacketsRecv-prev.PacketsRecv) / elapsed,
+					OutPacketsPerSec: float64(current.PacketsSent-prev.PacketsSent) / elapsed,
+				}
+				metrics.PerInterface[current.Name] = rate
+				metrics.InBytesPerSec += rate.InBytesPerSec
+				metrics.OutBytesPerSec += rate.OutBytesPerSec
+				metrics.InPacketsPerSec += rate.InPacketsPerSec
+				metrics.OutPacketsPerSec += rate.OutPacketsPerSec
+			}
+			lastNetStats[current.Name] = current
 		}
-		lastNetStats = current
 	}
 
 	diskStats, err := disk.IOCounters()
 	if err == nil {
-		var totalReadBytes, totalWriteBytes, totalReadOps, totalWriteOps uint64
-		for _, d := range diskStats {
-			totalReadBytes += d.ReadBytes
-			totalWriteBytes += d.WriteBytes
-			totalReadOps += d.ReadCount
-			totalWriteOps += d.WriteCount
-		}
-		if !lastNetDiskTime.IsZero() {
-			metrics.ReadKBytesPerSec = float64(totalReadBytes-lastDiskStats.ReadBytes) / elapsed / 1000
-			metrics.WriteKBytesPerSec = float64(totalWriteBytes-lastDiskStats.WriteBytes) / elapsed / 1000
-			metrics.ReadOpsPerSec = float64(totalReadOps-lastDiskStats.ReadCount) / elapsed
-			metrics.WriteOpsPerSec = float64(totalWriteOps-lastDiskStats.WriteCount) / elapsed
-		}
-		lastDiskStats = disk.IOCountersStat{
-			ReadBytes:  totalReadBytes,
-			WriteBytes: totalWriteBytes,
-			ReadCount:  totalReadOps,
-			WriteCount: totalWriteOps,
+		for name, current := range diskStats {
+			if !passesFilter(name, diskDeviceFilter) {
+				continue
+			}
+			if prev, ok := lastDiskStats[name]; ok && !first {
+				rate := DiskRate{
+					ReadKBytesPerSec:  float64(current.ReadBytes-prev.ReadBytes) / elapsed / 1000,
+					WriteKBytesPerSec: float64(current.WriteBytes-prev.WriteBytes) / elapsed / 1000,
+					ReadOpsPerSec:     float64(current.ReadCount-prev.ReadCount) / elapsed,
+					WriteOpsPerSec:    float64(current.WriteCount-prev.WriteCount) / elapsed,
+				}
+				metrics.PerDevice[name] = rate
+				metrics.ReadKBytesPerSec += rate.ReadKBytesPerSec
+				metrics.WriteKBytesPerSec += rate.WriteKBytesPerSec
+				metrics.ReadOpsPerSec += rate.ReadOpsPerSec
+				metrics.WriteOpsPerSec += rate.WriteOpsPerSec
+			}
+			lastDiskStats[name] = current
 		}
 	}
 
@@ -1337,7 +2381,7 @@ func getNetDiskMetrics() NetDiskMetrics {
 	return metrics
 }
 
-func collectMetrics(done chan struct{}, cpumetricsChan chan CPUMetrics, gpumetricsChan chan GPUMetrics, netdiskMetricsChan chan NetDiskMetrics) {
+func collectMetrics(done chan struct{}, cpumetricsChan chan CPUMetrics, gpumetricsChan chan GPUMetrics, netdiskMetricsChan chan NetDiskMetrics, batteryMetricsChan chan BatteryMetrics) {
 	cpumetricsChan <- CPUMetrics{}
 	gpumetricsChan <- GPUMetrics{}
 	netdiskMetricsChan <- NetDiskMetrics{}
@@ -1373,16 +2417,21 @@ func collectMetrics(done chan struct{}, cpumetricsChan chan CPUMetrics, gpumetri
 				DRAMW:     m.DRAMPower,
 				PackageW:  m.TotalPower,
 				Throttled: throttled,
-				SocTemp:   m.SocTemp,
+				SocTemp:   float64(m.SocTemp),
+				Clusters:  m.Clusters,
 			}
 
 			gpuMetrics := GPUMetrics{
-				FreqMHz: m.GPUFreqMHz,
+				FreqMHz: int(m.GPUFreqMHz),
 				Active:  int(m.GPUActive),
-				Temp:    m.SocTemp,
+				Temp:    float64(m.SocTemp),
 			}
 
-			netdiskMetrics := getNetDiskMetrics()
+			sampledNetDisk := adaptiveSamplerState == nil || adaptiveSamplerState.shouldRunNetDisk()
+			var netdiskMetrics NetDiskMetrics
+			if sampledNetDisk {
+				netdiskMetrics = getNetDiskMetrics()
+			}
 
 			select {
 			case cpumetricsChan <- cpuMetrics:
@@ -1392,47 +2441,78 @@ func collectMetrics(done chan struct{}, cpumetricsChan chan CPUMetrics, gpumetri
 			case gpumetricsChan <- gpuMetrics:
 			default:
 			}
-			select {
-			case netdiskMetricsChan <- netdiskMetrics:
-			default:
+			if sampledNetDisk {
+				select {
+				case netdiskMetricsChan <- netdiskMetrics:
+				default:
+				}
+			}
+
+			var battMetrics BatteryMetrics
+			if batteryPresent {
+				if batt, err := getBatteryMetrics(); err == nil {
+					battMetrics = batt
+					select {
+					case batteryMetricsChan <- batt:
+					default:
+					}
+				}
+			}
+
+			if activeRecorder != nil {
+				percentages, err := GetCPUPercentages()
+				cpuPercent := 0.0
+				if err == nil && len(percentages) > 0 {
+					var total float64
+					for _, p := range percentages {
+						total += p
+					}
+					cpuPercent = total / float64(len(percentages))
+				}
+				sample := RecordedSample{
+					Timestamp:  time.Now(),
+					CPU:        cpuMetrics,
+					GPU:        gpuMetrics,
+					Memory:     getMemoryMetrics(),
+					NetDisk:    netdiskMetrics,
+					Battery:    battMetrics,
+					Processes:  getProcessList(),
+					CPUPercent: cpuPercent,
+				}
+				if err := activeRecorder.Write(sample); err != nil {
+					stderrLogger.Printf("Failed to write recording sample: %v\n", err)
+				}
 			}
 		}
 	}
 }
 
+// processCPUModeIsRaw reports whether the process list should show ps aux's
+// %CPU column as-is (uncapped, so a process pegging 4 cores reads ~400%,
+// matching top/node_exporter's "raw" convention) instead of this repo's
+// longstanding default of dividing by runtime.NumCPU() so the column tops
+// out around 100% on a fully-loaded box. Defaults to normalized when unset,
+// including for config.json files saved before ProcessCPUMode existed.
+func processCPUModeIsRaw() bool {
+	return strings.EqualFold(currentConfig.ProcessCPUMode, "raw")
+}
+
+// getProcessList delegates to processSource (default psExecProcessSource,
+// see processsource.go for the gopsutil-based alternative and
+// --process-source). Neither source computes CPU%% from a raw tick counter
+// this repo diffs itself, so there's no prevState/wall-delta to guard
+// against going backwards or jumping on a sub-50ms redraw - both already
+// report CPU%% as a rate. The one real lever either leaves us is whether to
+// normalize that figure by core count; see processCPUModeIsRaw.
 func getProcessList() []ProcessMetrics {
-	cmd := exec.Command("ps", "aux")
-	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
-	output, err := cmd.Output()
+	if replayPath != "" {
+		return replayedProcesses
+	}
+	processes, err := processSource.List()
 	if err != nil {
 		log.Printf("Error getting process list: %v", err)
 		return nil
 	}
-	numCPU := float64(runtime.NumCPU())
-	processes := []ProcessMetrics{}
-	lines := strings.Split(string(output), "\n")
-	for _, line := range lines[1:] {
-		if line == "" {
-			continue
-		}
-		fields := strings.Fields(line)
-		if len(fields) < 11 {
-			continue
-		}
-		cpu, _ := strconv.ParseFloat(replaceCommas(fields[2]), 64)
-		cpu = cpu / numCPU
-		mem, _ := strconv.ParseFloat(replaceCommas(fields[3]), 64)
-		vsz, _ := strconv.ParseInt(fields[4], 10, 64)
-		rss, _ := strconv.ParseInt(fields[5], 10, 64)
-		pid, _ := strconv.Atoi(fields[1])
-		command := filepath.Base(fields[10])
-		process := ProcessMetrics{User: fields[0], PID: pid, CPU: cpu, Memory: mem, VSZ: vsz, RSS: rss, TTY: fields[6], State: fields[7], Started: fields[8], Time: fields[9], Command: command}
-		processes = append(processes, process)
-
-	}
-	sort.Slice(processes, func(i, j int) bool {
-		return processes[i].CPU > processes[j].CPU
-	})
 	return processes
 }
 
@@ -1469,32 +2549,109 @@ func updateTotalPowerChart(watts float64) {
 	sparkline.MaxVal = 8 // Match MaxHeight
 	sparklineGroup.Title = fmt.Sprintf("%.2f W Total (Max: %.2f W)", watts, maxPowerSeen)
 	sparkline.Title = fmt.Sprintf("Avg: %.2f W", avgWatts)
+
+	if powerBrailleGraph != nil {
+		powerBrailleSparkline.Data = powerValues
+		powerBrailleGraph.Title = sparklineGroup.Title
+	}
+}
+
+// updateCPUTimeUI renders cpuTimeWidget's aggregate and per-core
+// user/system/idle/nice breakdown from the tick deltas GetCPUPercentages
+// just computed, and feeds mactop_cpu_time_seconds_total.
+func updateCPUTimeUI() {
+	if len(lastCPUTimePct) == 0 {
+		return
+	}
+	var aggUser, aggSystem, aggIdle, aggNice float64
+	var aggUserSecs, aggSystemSecs, aggIdleSecs, aggNiceSecs float64
+	for i, pct := range lastCPUTimePct {
+		aggUser += pct.User
+		aggSystem += pct.System
+		aggIdle += pct.Idle
+		aggNice += pct.Nice
+
+		secs := lastCPUTimeDeltaSecs[i]
+		aggUserSecs += secs.User
+		aggSystemSecs += secs.System
+		aggIdleSecs += secs.Idle
+		aggNiceSecs += secs.Nice
+
+		core := strconv.Itoa(i)
+		cpuTimeSecondsTotal.With(prometheus.Labels{"mode": "user", "core": core}).Add(secs.User)
+		cpuTimeSecondsTotal.With(prometheus.Labels{"mode": "system", "core": core}).Add(secs.System)
+		cpuTimeSecondsTotal.With(prometheus.Labels{"mode": "idle", "core": core}).Add(secs.Idle)
+		if secs.Nice != 0 {
+			cpuTimeSecondsTotal.With(prometheus.Labels{"mode": "nice", "core": core}).Add(secs.Nice)
+		}
+	}
+	n := float64(len(lastCPUTimePct))
+	aggUser, aggSystem, aggIdle, aggNice = aggUser/n, aggSystem/n, aggIdle/n, aggNice/n
+
+	cpuTimeSecondsTotal.With(prometheus.Labels{"mode": "user", "core": "all"}).Add(aggUserSecs)
+	cpuTimeSecondsTotal.With(prometheus.Labels{"mode": "system", "core": "all"}).Add(aggSystemSecs)
+	cpuTimeSecondsTotal.With(prometheus.Labels{"mode": "idle", "core": "all"}).Add(aggIdleSecs)
+	if aggNiceSecs != 0 {
+		cpuTimeSecondsTotal.With(prometheus.Labels{"mode": "nice", "core": "all"}).Add(aggNiceSecs)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("user: %.1f%%  sys: %.1f%%  idle: %.1f%%", aggUser, aggSystem, aggIdle))
+	if aggNice > 0.05 {
+		sb.WriteString(fmt.Sprintf("  nice: %.1f%%", aggNice))
+	}
+	sb.WriteString("\n")
+	for i, pct := range lastCPUTimePct {
+		sb.WriteString(fmt.Sprintf("c%d: u%.0f/s%.0f/i%.0f  ", i, pct.User, pct.System, pct.Idle))
+		if (i+1)%4 == 0 {
+			sb.WriteString("\n")
+		}
+	}
+	cpuTimeWidget.Text = strings.TrimRight(sb.String(), "\n ")
 }
 
 func updateCPUUI(cpuMetrics CPUMetrics) {
+	lastPackageWatts = cpuMetrics.PackageW
+	lastThrottled = cpuMetrics.Throttled
 	coreUsages, err := GetCPUPercentages()
 	if err != nil {
 		stderrLogger.Printf("Error getting CPU percentages: %v\n", err)
 		return
 	}
 	cpuCoreWidget.UpdateUsage(coreUsages)
+	updateCPUTimeUI()
+	updateSystemStatsUI()
+	if statusBarEnabled {
+		updateAlertBar()
+	}
 	var totalUsage float64
 	for _, usage := range coreUsages {
 		totalUsage += usage
 	}
 	totalUsage /= float64(len(coreUsages))
 	cpuGauge.Percent = int(totalUsage)
-	cpuGauge.Title = fmt.Sprintf("mactop - %d Cores (%dE/%dP) - CPU Usage: %.2f%%",
+
+	load1, load5, load15 := getLoadAverages()
+	pressureLevel, _ := getMemoryPressureLevel()
+	loadAverage.With(prometheus.Labels{"window": "1m"}).Set(load1)
+	loadAverage.With(prometheus.Labels{"window": "5m"}).Set(load5)
+	loadAverage.With(prometheus.Labels{"window": "15m"}).Set(load15)
+	memoryPressure.Set(float64(pressureLevel))
+
+	cpuGauge.Title = fmt.Sprintf("mactop - %d Cores (%dE/%dP) - CPU Usage: %.2f%% - Load: %.2f %.2f %.2f - Mem Pressure: %s",
 		cpuCoreWidget.eCoreCount+cpuCoreWidget.pCoreCount,
 		cpuCoreWidget.eCoreCount,
 		cpuCoreWidget.pCoreCount,
 		totalUsage,
+		load1, load5, load15,
+		memoryPressureLabel(pressureLevel),
 	)
-	cpuCoreWidget.Title = fmt.Sprintf("mactop - %d Cores (%dE/%dP) %.2f%%",
+	cpuCoreWidget.Title = fmt.Sprintf("mactop - %d Cores (%dE/%dP) %.2f%% - Load: %.2f %.2f %.2f",
 		cpuCoreWidget.eCoreCount+cpuCoreWidget.pCoreCount,
 		cpuCoreWidget.eCoreCount,
 		cpuCoreWidget.pCoreCount,
 		totalUsage,
+		load1, load5, load15,
 	)
 	aneUtil := float64(cpuMetrics.ANEW / 1 / 8.0 * 100)
 	aneGauge.Title = fmt.Sprintf("ANE Usage: %.2f%% @ %.2f W", aneUtil, cpuMetrics.ANEW)
@@ -1506,13 +2663,15 @@ func updateCPUUI(cpuMetrics CPUMetrics) {
 		tempStr = fmt.Sprintf(" @ %.0f°C", cpuMetrics.SocTemp)
 	}
 	PowerChart.Title = fmt.Sprintf("%.1fW Total%s", cpuMetrics.PackageW, tempStr)
-	PowerChart.Text = fmt.Sprintf("CPU: %.2f W | GPU: %.2f W\nANE: %.2f W | DRAM: %.2f W\nTotal: %.2f W | %s",
+	PowerChart.Text = fmt.Sprintf("CPU: %.2f W | GPU: %.2f W\nANE: %.2f W | DRAM: %.2f W\nTotal: %.2f W | %s%s\n%s",
 		cpuMetrics.CPUW,
 		cpuMetrics.GPUW,
 		cpuMetrics.ANEW,
 		cpuMetrics.DRAMW,
 		cpuMetrics.PackageW,
 		thermalStr,
+		renderClusterHistogram(cpuMetrics.Clusters),
+		energyFooter(),
 	)
 	memoryMetrics := getMemoryMetrics()
 	memoryGauge.Title = fmt.Sprintf("Memory Usage: %.2f GB / %.2f GB (Swap: %.2f/%.2f GB)", float64(memoryMetrics.Used)/1024/1024/1024, float64(memoryMetrics.Total)/1024/1024/1024, float64(memoryMetrics.SwapUsed)/1024/1024/1024, float64(memoryMetrics.SwapTotal)/1024/1024/1024)
@@ -1532,6 +2691,17 @@ func updateCPUUI(cpuMetrics CPUMetrics) {
 		pcoreAvg /= float64(cpuCoreWidget.pCoreCount)
 	}
 
+	recordCPUSample(cpuMetrics, coreUsages, totalUsage, ecoreAvg, pcoreAvg, cpuCoreWidget.eCoreCount, memoryMetrics)
+}
+
+// recordCPUSample sets the mactop_* CPU/memory Prometheus gauges, updates the
+// OTLP observable state, evaluates alert rules, routes the sample to any
+// configured metrics sinks, and accumulates energy/history - everything
+// updateCPUUI drives that isn't tied to a termui widget. This lets the
+// headless/batch sampling loops (runHeadless, runBatch) call it directly, so
+// --exporter-only and --batch publish real data instead of leaving these
+// series frozen at zero.
+func recordCPUSample(cpuMetrics CPUMetrics, coreUsages []float64, totalUsage, ecoreAvg, pcoreAvg float64, eCoreCount int, memoryMetrics MemoryMetrics) {
 	thermalStateVal, _ := getThermalStateString()
 	thermalStateNum := 0
 	switch thermalStateVal {
@@ -1546,6 +2716,13 @@ func updateCPUUI(cpuMetrics CPUMetrics) {
 	cpuUsage.Set(totalUsage)
 	ecoreUsage.Set(ecoreAvg)
 	pcoreUsage.Set(pcoreAvg)
+	for i, usage := range coreUsages {
+		cluster, core := "E", i
+		if i >= eCoreCount {
+			cluster, core = "P", i-eCoreCount
+		}
+		cpuCoreUsage.With(prometheus.Labels{"cluster": cluster, "core": strconv.Itoa(core)}).Set(usage)
+	}
 	powerUsage.With(prometheus.Labels{"component": "cpu"}).Set(cpuMetrics.CPUW)
 	powerUsage.With(prometheus.Labels{"component": "gpu"}).Set(cpuMetrics.GPUW)
 	powerUsage.With(prometheus.Labels{"component": "ane"}).Set(cpuMetrics.ANEW)
@@ -1553,11 +2730,80 @@ func updateCPUUI(cpuMetrics CPUMetrics) {
 	powerUsage.With(prometheus.Labels{"component": "total"}).Set(cpuMetrics.PackageW)
 	socTemp.Set(cpuMetrics.SocTemp)
 	thermalState.Set(float64(thermalStateNum))
+	for _, level := range []string{"nominal", "fair", "serious", "critical"} {
+		val := 0.0
+		if level == strings.ToLower(thermalStateVal) {
+			val = 1.0
+		}
+		thermalPressure.With(prometheus.Labels{"level": level}).Set(val)
+	}
 
 	memoryUsage.With(prometheus.Labels{"type": "used"}).Set(float64(memoryMetrics.Used) / 1024 / 1024 / 1024)
 	memoryUsage.With(prometheus.Labels{"type": "total"}).Set(float64(memoryMetrics.Total) / 1024 / 1024 / 1024)
 	memoryUsage.With(prometheus.Labels{"type": "swap_used"}).Set(float64(memoryMetrics.SwapUsed) / 1024 / 1024 / 1024)
 	memoryUsage.With(prometheus.Labels{"type": "swap_total"}).Set(float64(memoryMetrics.SwapTotal) / 1024 / 1024 / 1024)
+
+	memUtil := 0.0
+	if memoryMetrics.Total > 0 {
+		memUtil = float64(memoryMetrics.Used) / float64(memoryMetrics.Total)
+	}
+	recordOTLPCPUMem(totalUsage, memUtil, cpuMetrics.ANEW)
+
+	evaluateAlerts(map[string]float64{
+		"cpu":           totalUsage,
+		"soc_temp":      cpuMetrics.SocTemp,
+		"mem_used":      memUtil * 100,
+		"power_total":   cpuMetrics.PackageW,
+		"thermal_state": float64(thermalStateNum),
+		"swap_used":     float64(memoryMetrics.SwapUsed) / 1024 / 1024 / 1024,
+	}, getProcessList())
+
+	routeSocSample(cpuMetrics)
+	accumulateEnergy(cpuMetrics, float64(updateInterval)/1000, getProcessList())
+
+	recordHistoryPoint(HistoryPoint{
+		Timestamp:    time.Now(),
+		CPUPercent:   totalUsage,
+		CPUWatts:     cpuMetrics.CPUW,
+		GPUWatts:     cpuMetrics.GPUW,
+		ANEWatts:     cpuMetrics.ANEW,
+		DRAMWatts:    cpuMetrics.DRAMW,
+		PackageW:     cpuMetrics.PackageW,
+		SocTemp:      cpuMetrics.SocTemp,
+		MemUsedGB:    float64(memoryMetrics.Used) / 1024 / 1024 / 1024,
+		DiskReadKBs:  lastDiskNetRate.DiskReadKBs,
+		DiskWriteKBs: lastDiskNetRate.DiskWriteKBs,
+		NetInKBs:     lastDiskNetRate.NetInKBs,
+		NetOutKBs:    lastDiskNetRate.NetOutKBs,
+	})
+}
+
+func updateBatteryUI(batt BatteryMetrics) {
+	lastBatteryMetrics = batt
+	if !batteryPresent || batteryGauge == nil {
+		return
+	}
+	batteryGauge.Percent = batt.PercentCharge
+	status := "Discharging"
+	if batt.ACPower && batt.Charging {
+		status = "Charging"
+	} else if batt.ACPower {
+		status = "AC Power"
+	}
+	remaining := ""
+	if batt.TimeRemaining != "" {
+		remaining = fmt.Sprintf(" (%s)", batt.TimeRemaining)
+	}
+	batteryGauge.Title = fmt.Sprintf("Battery: %d%% %s%s | %d cycles", batt.PercentCharge, status, remaining, batt.CycleCount)
+
+	for i := 0; i < len(batteryValues)-1; i++ {
+		batteryValues[i] = batteryValues[i+1]
+	}
+	// Sparkline data can't be negative, so shift net power flow into a
+	// positive range: 50 is "zero flow", above is charging, below discharging.
+	batteryValues[len(batteryValues)-1] = 50 + batt.WattsNow
+	batterySparkline.Data = batteryValues
+	batterySparklineGroup.Title = fmt.Sprintf("Power Flow: %+.1fW", batt.WattsNow)
 }
 
 func updateGPUUI(gpuMetrics GPUMetrics) {
@@ -1592,8 +2838,62 @@ func updateGPUUI(gpuMetrics GPUMetrics) {
 	gpuSparkline.MaxVal = 100 // GPU usage is 0-100%
 	gpuSparklineGroup.Title = fmt.Sprintf("GPU History: %d%% (Avg: %.1f%%)", gpuMetrics.Active, avgGPU)
 
+	if gpuBrailleGraph != nil {
+		gpuBrailleSparkline.Data = gpuValues
+		gpuBrailleGraph.Title = gpuSparklineGroup.Title
+	}
+
+	recordGPUSample(gpuMetrics)
+}
+
+// recordGPUSample sets the mactop_gpu_* Prometheus gauges and the OTLP GPU
+// observable, independent of any termui widget, so the headless/batch
+// sampling loops can call it the same way they call recordCPUSample.
+func recordGPUSample(gpuMetrics GPUMetrics) {
 	gpuUsage.Set(float64(gpuMetrics.Active))
 	gpuFreqMHz.Set(float64(gpuMetrics.FreqMHz))
+	gpuActiveRatio.Set(float64(gpuMetrics.Active) / 100)
+	recordOTLPGPU(float64(gpuMetrics.Active))
+}
+
+// recordSamples drives recordCPUSample/recordGPUSample for a non-interactive
+// sampling loop (runHeadless, runBatch), deriving the per-core usage split
+// and E/P-core counts updateCPUUI otherwise reads off cpuCoreWidget from
+// getSOCInfo instead, since no termui widget exists outside the TUI.
+func recordSamples(cpuMetrics CPUMetrics, gpuMetrics GPUMetrics) {
+	coreUsages, err := GetCPUPercentages()
+	if err != nil {
+		stderrLogger.Printf("Error getting CPU percentages: %v\n", err)
+		return
+	}
+	var totalUsage float64
+	for _, usage := range coreUsages {
+		totalUsage += usage
+	}
+	if len(coreUsages) > 0 {
+		totalUsage /= float64(len(coreUsages))
+	}
+
+	socInfo := getSOCInfo()
+	eCoreCount, _ := socInfo["e_core_count"].(int)
+	pCoreCount, _ := socInfo["p_core_count"].(int)
+
+	var ecoreAvg, pcoreAvg float64
+	if eCoreCount > 0 && len(coreUsages) >= eCoreCount {
+		for i := 0; i < eCoreCount; i++ {
+			ecoreAvg += coreUsages[i]
+		}
+		ecoreAvg /= float64(eCoreCount)
+	}
+	if pCoreCount > 0 && len(coreUsages) >= eCoreCount+pCoreCount {
+		for i := eCoreCount; i < eCoreCount+pCoreCount; i++ {
+			pcoreAvg += coreUsages[i]
+		}
+		pcoreAvg /= float64(pCoreCount)
+	}
+
+	recordCPUSample(cpuMetrics, coreUsages, totalUsage, ecoreAvg, pcoreAvg, eCoreCount, getMemoryMetrics())
+	recordGPUSample(gpuMetrics)
 }
 
 type VolumeInfo struct {
@@ -1655,27 +2955,130 @@ func getVolumes() []VolumeInfo {
 
 func updateNetDiskUI(netdiskMetrics NetDiskMetrics) {
 	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("Net: ↑ %.0fKB/s ↓ %.0fKB/s\n",
-		netdiskMetrics.OutBytesPerSec, netdiskMetrics.InBytesPerSec))
-	sb.WriteString(fmt.Sprintf("I/O: R %.0fKB/s W %.0fKB/s\n",
-		netdiskMetrics.ReadKBytesPerSec, netdiskMetrics.WriteKBytesPerSec))
+	sb.WriteString(fmt.Sprintf("Net: ↑ %s ↓ %s\n",
+		formatRate(netdiskMetrics.OutBytesPerSec), formatRate(netdiskMetrics.InBytesPerSec)))
+	sb.WriteString(fmt.Sprintf("I/O: R %s W %s\n",
+		formatRate(netdiskMetrics.ReadKBytesPerSec), formatRate(netdiskMetrics.WriteKBytesPerSec)))
+
+	for _, name := range topBusiestInterfaces(netdiskMetrics.PerInterface, 3) {
+		rate := netdiskMetrics.PerInterface[name]
+		sb.WriteString(fmt.Sprintf("  %s: ↑ %s ↓ %s\n",
+			name, formatRate(rate.OutBytesPerSec), formatRate(rate.InBytesPerSec)))
+	}
+	for _, name := range topBusiestDevices(netdiskMetrics.PerDevice, 3) {
+		rate := netdiskMetrics.PerDevice[name]
+		sb.WriteString(fmt.Sprintf("  %s: R %s W %s\n",
+			name, formatRate(rate.ReadKBytesPerSec), formatRate(rate.WriteKBytesPerSec)))
+	}
 
 	volumes := getVolumes()
+	minFreeGB := -1.0
 	for i, v := range volumes {
 		if i >= 3 {
 			break
 		}
 		sb.WriteString(fmt.Sprintf("%s: %.0f/%.0fGB (%.0fGB free)\n",
 			v.Name, v.Used, v.Total, v.Available))
+		if minFreeGB < 0 || v.Available < minFreeGB {
+			minFreeGB = v.Available
+		}
 	}
 	NetworkInfo.Text = strings.TrimSuffix(sb.String(), "\n")
+	if minFreeGB >= 0 {
+		evaluateAlerts(map[string]float64{"disk_free": minFreeGB}, nil)
+	}
+	evaluateAlerts(map[string]float64{
+		"disk_read_kbps":  netdiskMetrics.ReadKBytesPerSec,
+		"disk_write_kbps": netdiskMetrics.WriteKBytesPerSec,
+	}, nil)
+
+	lastDiskNetRate = diskNetRate{
+		DiskReadKBs:  netdiskMetrics.ReadKBytesPerSec,
+		DiskWriteKBs: netdiskMetrics.WriteKBytesPerSec,
+		NetInKBs:     netdiskMetrics.InBytesPerSec,
+		NetOutKBs:    netdiskMetrics.OutBytesPerSec,
+	}
 
 	networkSpeed.With(prometheus.Labels{"direction": "upload"}).Set(netdiskMetrics.OutBytesPerSec)
 	networkSpeed.With(prometheus.Labels{"direction": "download"}).Set(netdiskMetrics.InBytesPerSec)
+	networkBitsPerSec.With(prometheus.Labels{"direction": "upload"}).Set(netdiskMetrics.OutBytesPerSec * 1024 * 8)
+	networkBitsPerSec.With(prometheus.Labels{"direction": "download"}).Set(netdiskMetrics.InBytesPerSec * 1024 * 8)
 	diskIOSpeed.With(prometheus.Labels{"operation": "read"}).Set(netdiskMetrics.ReadKBytesPerSec)
 	diskIOSpeed.With(prometheus.Labels{"operation": "write"}).Set(netdiskMetrics.WriteKBytesPerSec)
 	diskIOPS.With(prometheus.Labels{"operation": "read"}).Set(netdiskMetrics.ReadOpsPerSec)
 	diskIOPS.With(prometheus.Labels{"operation": "write"}).Set(netdiskMetrics.WriteOpsPerSec)
+
+	for name, rate := range netdiskMetrics.PerInterface {
+		networkSpeedByIface.With(prometheus.Labels{"interface": name, "direction": "upload"}).Set(rate.OutBytesPerSec)
+		networkSpeedByIface.With(prometheus.Labels{"interface": name, "direction": "download"}).Set(rate.InBytesPerSec)
+	}
+	for name, rate := range netdiskMetrics.PerDevice {
+		diskIOSpeedByDevice.With(prometheus.Labels{"device": name, "operation": "read"}).Set(rate.ReadKBytesPerSec)
+		diskIOSpeedByDevice.With(prometheus.Labels{"device": name, "operation": "write"}).Set(rate.WriteKBytesPerSec)
+	}
+}
+
+// topBusiestInterfaces returns up to n interface names from rates, ranked by
+// total (in+out) bytes/sec descending, for updateNetDiskUI's breakdown lines.
+func topBusiestInterfaces(rates map[string]IfaceRate, n int) []string {
+	names := make([]string, 0, len(rates))
+	for name := range rates {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		a, b := rates[names[i]], rates[names[j]]
+		return a.InBytesPerSec+a.OutBytesPerSec > b.InBytesPerSec+b.OutBytesPerSec
+	})
+	if len(names) > n {
+		names = names[:n]
+	}
+	return names
+}
+
+// topBusiestDevices is topBusiestInterfaces's disk-device equivalent, ranked
+// by total (read+write) KB/s descending.
+func topBusiestDevices(rates map[string]DiskRate, n int) []string {
+	names := make([]string, 0, len(rates))
+	for name := range rates {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		a, b := rates[names[i]], rates[names[j]]
+		return a.ReadKBytesPerSec+a.WriteKBytesPerSec > b.ReadKBytesPerSec+b.WriteKBytesPerSec
+	})
+	if len(names) > n {
+		names = names[:n]
+	}
+	return names
+}
+
+// clusterBars are the eight Unicode block levels renderClusterHistogram
+// uses for its mini-histogram, the same "one glyph per bucket" approach
+// braillewidgets.go's sparklines use for per-core usage.
+var clusterBars = []rune("▁▂▃▄▅▆▇█")
+
+// renderClusterHistogram renders one "\nNAME ████░░░░ 2988MHz" line per
+// cluster, so the TUI surfaces the per-P/E-core (and per-GPU-cluster)
+// power/DVFS breakdown chunk4-4 asked for without a whole new widget.
+// Empty on platforms/paths where ClusterMetrics isn't populated (see
+// ioreport_cgo.go's sampleSocMetrics doc comment).
+func renderClusterHistogram(clusters []ClusterMetrics) string {
+	if len(clusters) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	for _, c := range clusters {
+		barIdx := int(c.ActiveResidency * float64(len(clusterBars)-1))
+		if barIdx < 0 {
+			barIdx = 0
+		}
+		if barIdx >= len(clusterBars) {
+			barIdx = len(clusterBars) - 1
+		}
+		sb.WriteString(fmt.Sprintf("\n%-6s %c %3.0f%% @ %4.0fMHz %.2fW",
+			c.Name, clusterBars[barIdx], c.ActiveResidency*100, c.EffectiveFreqMHz, c.PowerW))
+	}
+	return sb.String()
 }
 
 func max(nums ...int) int {
@@ -1688,28 +3091,38 @@ func max(nums ...int) int {
 	return maxVal
 }
 
+// getSOCInfo returns the machine's static CPU/GPU identity, computed once
+// and cached for the life of the process (see socInfoOnce).
 func getSOCInfo() map[string]interface{} {
-	cpuInfoDict := getCPUInfo()
-	coreCountsDict := getCoreCounts()
-	var eCoreCounts, pCoreCounts int
-	if val, ok := coreCountsDict["hw.perflevel1.logicalcpu"]; ok {
-		eCoreCounts = val
-	}
-	if val, ok := coreCountsDict["hw.perflevel0.logicalcpu"]; ok {
-		pCoreCounts = val
-	}
-	socInfo := map[string]interface{}{
-		"name":           cpuInfoDict["machdep.cpu.brand_string"],
-		"core_count":     cpuInfoDict["machdep.cpu.core_count"],
-		"cpu_max_power":  nil,
-		"gpu_max_power":  nil,
-		"cpu_max_bw":     nil,
-		"gpu_max_bw":     nil,
-		"e_core_count":   eCoreCounts,
-		"p_core_count":   pCoreCounts,
-		"gpu_core_count": getGPUCores(),
-	}
-	return socInfo
+	socInfoOnce.Do(func() {
+		cpuInfoDict, err := getCPUInfo()
+		if err != nil {
+			stderrLogger.Printf("getSOCInfo: %v\n", err)
+			cpuInfoDict = map[string]string{}
+		}
+		coreCountsDict, err := getCoreCounts()
+		if err != nil {
+			stderrLogger.Printf("getSOCInfo: %v\n", err)
+			coreCountsDict = map[string]int{}
+		}
+		gpuCores, err := getGPUCores()
+		if err != nil {
+			stderrLogger.Printf("getSOCInfo: %v\n", err)
+		}
+
+		cachedSOCInfo = map[string]interface{}{
+			"name":           cpuInfoDict["machdep.cpu.brand_string"],
+			"core_count":     cpuInfoDict["machdep.cpu.core_count"],
+			"cpu_max_power":  nil,
+			"gpu_max_power":  nil,
+			"cpu_max_bw":     nil,
+			"gpu_max_bw":     nil,
+			"e_core_count":   coreCountsDict["hw.perflevel1.logicalcpu"],
+			"p_core_count":   coreCountsDict["hw.perflevel0.logicalcpu"],
+			"gpu_core_count": gpuCores,
+		}
+	})
+	return cachedSOCInfo
 }
 
 func getMemoryMetrics() MemoryMetrics {
@@ -1729,65 +3142,134 @@ func getMemoryMetrics() MemoryMetrics {
 	}
 }
 
-func getCPUInfo() map[string]string {
-	out, err := exec.Command("sysctl", "machdep.cpu").Output()
+// getLoadAverages reports the 1/5/15-minute load averages via gopsutil's
+// load.Avg(), for the header line next to the core count and the
+// mactop_load_average gauge.
+func getLoadAverages() (load1, load5, load15 float64) {
+	avg, err := load.Avg()
 	if err != nil {
-		stderrLogger.Fatalf("failed to execute getCPUInfo() sysctl command: %v", err)
+		return 0, 0, 0
 	}
-	cpuInfo := string(out)
-	cpuInfoLines := strings.Split(cpuInfo, "\n")
-	dataFields := []string{"machdep.cpu.brand_string", "machdep.cpu.core_count"}
-	cpuInfoDict := make(map[string]string)
-	for _, line := range cpuInfoLines {
-		for _, field := range dataFields {
-			if strings.Contains(line, field) {
-				value := strings.TrimSpace(strings.Split(line, ":")[1])
-				cpuInfoDict[field] = value
+	return avg.Load1, avg.Load5, avg.Load15
+}
+
+// memoryPressureLevel mirrors macOS's own "normal"/"warn"/"critical"
+// memory-pressure states, 0/1/2, for mactop_memory_pressure.
+const (
+	memoryPressureNormal = iota
+	memoryPressureWarn
+	memoryPressureCritical
+)
+
+// getMemoryPressureLevel reports macOS's memory-pressure state and the
+// system-wide free-page percentage behind it, via `memory_pressure -Q` (a
+// single-shot query; the bare command instead blocks forever waiting for a
+// pressure-level change, so it's unusable here). Falls back to the
+// available/total ratio from mem.VirtualMemory if the command is missing or
+// fails, e.g. when running under a sandboxed or non-macOS environment.
+func getMemoryPressureLevel() (level int, freePercent float64) {
+	found := false
+	if out, err := exec.Command("memory_pressure", "-Q").Output(); err == nil {
+		for _, line := range strings.Split(string(out), "\n") {
+			idx := strings.Index(line, "free percentage:")
+			if idx == -1 {
+				continue
+			}
+			pctStr := strings.TrimSuffix(strings.TrimSpace(line[idx+len("free percentage:"):]), "%")
+			if pct, perr := strconv.ParseFloat(pctStr, 64); perr == nil {
+				freePercent = pct
+				found = true
 			}
 		}
 	}
-	return cpuInfoDict
+	if !found {
+		if v, err := mem.VirtualMemory(); err == nil && v.Total > 0 {
+			freePercent = float64(v.Available) / float64(v.Total) * 100
+		}
+	}
+	switch {
+	case freePercent >= 50:
+		level = memoryPressureNormal
+	case freePercent >= 10:
+		level = memoryPressureWarn
+	default:
+		level = memoryPressureCritical
+	}
+	return level, freePercent
 }
 
-func getCoreCounts() map[string]int {
-	cmd := exec.Command("sysctl", "hw.perflevel0.logicalcpu", "hw.perflevel1.logicalcpu")
-	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
-	out, err := cmd.Output()
+func memoryPressureLabel(level int) string {
+	switch level {
+	case memoryPressureWarn:
+		return "Warn"
+	case memoryPressureCritical:
+		return "Critical"
+	default:
+		return "Normal"
+	}
+}
+
+// getCPUInfo reads machdep.cpu.brand_string/core_count via unix.Sysctl
+// directly rather than shelling out to `sysctl` and parsing its text output,
+// so a missing/renamed sysctl returns an error instead of a panic on
+// unexpected output or a Fatalf that kills the whole TUI.
+func getCPUInfo() (map[string]string, error) {
+	brand, err := unix.Sysctl("machdep.cpu.brand_string")
 	if err != nil {
-		stderrLogger.Fatalf("failed to execute getCoreCounts() sysctl command: %v", err)
+		return nil, fmt.Errorf("sysctl machdep.cpu.brand_string: %w", err)
 	}
-	coresInfo := string(out)
-	coresInfoLines := strings.Split(coresInfo, "\n")
-	dataFields := []string{"hw.perflevel0.logicalcpu", "hw.perflevel1.logicalcpu"}
-	coresInfoDict := make(map[string]int)
-	for _, line := range coresInfoLines {
-		for _, field := range dataFields {
-			if strings.Contains(line, field) {
-				value, _ := strconv.Atoi(strings.TrimSpace(strings.Split(line, ":")[1]))
-				coresInfoDict[field] = value
-			}
-		}
+	coreCount, err := unix.SysctlUint32("machdep.cpu.core_count")
+	if err != nil {
+		return nil, fmt.Errorf("sysctl machdep.cpu.core_count: %w", err)
+	}
+	return map[string]string{
+		"machdep.cpu.brand_string": brand,
+		"machdep.cpu.core_count":   strconv.Itoa(int(coreCount)),
+	}, nil
+}
+
+// getCoreCounts reads the E/P-core counts via unix.SysctlUint32, the same
+// sysctls the old `sysctl hw.perflevel0.logicalcpu hw.perflevel1.logicalcpu`
+// shell-out parsed from text.
+func getCoreCounts() (map[string]int, error) {
+	pCores, err := unix.SysctlUint32("hw.perflevel0.logicalcpu")
+	if err != nil {
+		return nil, fmt.Errorf("sysctl hw.perflevel0.logicalcpu: %w", err)
+	}
+	eCores, err := unix.SysctlUint32("hw.perflevel1.logicalcpu")
+	if err != nil {
+		return nil, fmt.Errorf("sysctl hw.perflevel1.logicalcpu: %w", err)
 	}
-	return coresInfoDict
+	return map[string]int{
+		"hw.perflevel0.logicalcpu": int(pCores),
+		"hw.perflevel1.logicalcpu": int(eCores),
+	}, nil
 }
 
-func getGPUCores() string {
-	cmd, err := exec.Command("system_profiler", "-detailLevel", "basic", "SPDisplaysDataType").Output()
+// getGPUCores shells out to system_profiler, since GPU core count has no
+// sysctl equivalent; "?" is returned (not a fatal exit) if the command or
+// its output doesn't parse as expected.
+func getGPUCores() (string, error) {
+	out, err := exec.Command("system_profiler", "-detailLevel", "basic", "SPDisplaysDataType").Output()
 	if err != nil {
-		stderrLogger.Fatalf("failed to execute system_profiler command: %v", err)
+		return "?", fmt.Errorf("system_profiler SPDisplaysDataType: %w", err)
 	}
-	output := string(cmd)
-	stderrLogger.Printf("Output: %s\n", output)
-	lines := strings.Split(output, "\n")
-	for _, line := range lines {
+	for _, line := range strings.Split(string(out), "\n") {
 		if strings.Contains(line, "Total Number of Cores") {
 			parts := strings.Split(line, ": ")
 			if len(parts) > 1 {
-				cores := strings.TrimSpace(parts[1])
-				return cores
+				return strings.TrimSpace(parts[1]), nil
 			}
 			break
 		}
 	}
-	return "?"
+	return "?", nil
 }
+
+// socInfoOnce caches getCPUInfo/getCoreCounts/getGPUCores' results: they're
+// invariant for the life of the process, so there's no reason to re-read
+// them (shell-out or sysctl) beyond the first call.
+var (
+	socInfoOnce   sync.Once
+	cachedSOCInfo map[string]interface{}
+)
@@ -0,0 +1,152 @@
+// Copyright (c) 2024-2026 Carsen Klock under MIT License
+// Package router fans a metric sample out to one or more pluggable sinks
+// (stdout JSON lines, InfluxDB line protocol, a Unix socket, Prometheus),
+// applying per-metric exclude/rename/add_tags/del_tags rules first so
+// downstream TSDBs see a stable series name and tag set regardless of how
+// mactop's own field names evolve. Modeled loosely on the cc-metric-
+// collector router: one small Sink interface, config-driven rules, no
+// sampler-side knowledge of which sinks are active.
+package router
+
+import (
+	"fmt"
+	"time"
+)
+
+// Sample is one metric reading. Unit carries the IOReport-style unit label
+// (nJ, mJ, uW, mW, C, Hz, ...) it arrived in, if known; Normalize rescales
+// Value to the canonical SI unit (W, C, Hz) and clears Unit to that
+// canonical form.
+type Sample struct {
+	Name      string
+	Value     float64
+	Unit      string
+	Tags      map[string]string
+	Timestamp time.Time
+}
+
+// Sink is the extension point: anything that can durably or remotely
+// record a sample. Add a new sink by implementing this, not by touching
+// the sampler or Router.
+type Sink interface {
+	Write(sample Sample) error
+}
+
+// Rule is one per-metric routing rule, keyed by the sample's original Name
+// in Config.Rules.
+type Rule struct {
+	Exclude bool              `json:"exclude"`
+	Rename  string            `json:"rename"`
+	AddTags map[string]string `json:"add_tags"`
+	DelTags []string          `json:"del_tags"`
+}
+
+// SinkConfig describes one entry of the --metrics-config file's "sinks"
+// array. Type selects which sink main.go constructs; the remaining fields
+// are only meaningful for some types (see metrics/router/sinks).
+type SinkConfig struct {
+	Type  string `json:"type"` // "stdout", "influx_udp", "influx_http", "unix_socket", "prometheus"
+	Addr  string `json:"addr,omitempty"`
+	URL   string `json:"url,omitempty"`
+	Token string `json:"token,omitempty"`
+	Path  string `json:"path,omitempty"`
+}
+
+// Config is the shape of the --metrics-config JSON file.
+type Config struct {
+	Rules map[string]Rule `json:"rules"`
+	Sinks []SinkConfig    `json:"sinks"`
+}
+
+// Router applies Config's rules to each sample, then fans the result out to
+// every registered Sink. A sink error is logged by the caller (via the
+// returned per-sink errors from Route), not fatal to the others.
+type Router struct {
+	rules map[string]Rule
+	sinks []Sink
+}
+
+// New builds a Router from cfg. Sinks are registered separately via
+// AddSink, since they're constructed from --metrics-config's sink-specific
+// settings by the caller (main.go), not by this package.
+func New(cfg Config) *Router {
+	return &Router{rules: cfg.Rules}
+}
+
+// AddSink registers a sink that every routed sample will be written to
+// (unless a rule excludes that sample's metric first).
+func (r *Router) AddSink(s Sink) {
+	r.sinks = append(r.sinks, s)
+}
+
+// Route applies cfg rules to each sample and writes the surviving ones to
+// every sink, collecting (not stopping on) per-sink write errors.
+func (r *Router) Route(samples []Sample) []error {
+	var errs []error
+	for _, s := range samples {
+		s = Normalize(s)
+		rule, ok := r.rules[s.Name]
+		if ok {
+			if rule.Exclude {
+				continue
+			}
+			if rule.Rename != "" {
+				s.Name = rule.Rename
+			}
+			if len(rule.AddTags) > 0 || len(rule.DelTags) > 0 {
+				tags := make(map[string]string, len(s.Tags)+len(rule.AddTags))
+				for k, v := range s.Tags {
+					tags[k] = v
+				}
+				for _, del := range rule.DelTags {
+					delete(tags, del)
+				}
+				for k, v := range rule.AddTags {
+					tags[k] = v
+				}
+				s.Tags = tags
+			}
+		}
+		for _, sink := range r.sinks {
+			if err := sink.Write(s); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", s.Name, err))
+			}
+		}
+	}
+	return errs
+}
+
+// unitScale maps an IOReport-style unit label to the multiplier that
+// converts it to its canonical SI unit, and the canonical unit's own
+// label. mactop's own IOReport sampling (ioreport_cgo.go/ioreport_purego.go)
+// already rescales nJ/mJ energy-over-duration into watts before a
+// SocMetrics ever reaches this package, so in practice Normalize is a
+// no-op for mactop's built-in samples; it exists so a sink or a future
+// collector that hands this package raw IOReport units doesn't have to
+// duplicate the table itself.
+var unitScale = map[string]struct {
+	factor    float64
+	canonical string
+}{
+	"nJ":  {1e-9, "J"},
+	"mJ":  {1e-3, "J"},
+	"uW":  {1e-6, "W"},
+	"mW":  {1e-3, "W"},
+	"W":   {1, "W"},
+	"C":   {1, "C"},
+	"Hz":  {1, "Hz"},
+	"MHz": {1e6, "Hz"},
+}
+
+// Normalize rescales sample.Value to its canonical SI unit when Unit names
+// a known non-canonical label; samples with no Unit, or an already-
+// canonical one, pass through unchanged.
+func Normalize(sample Sample) Sample {
+	scale, ok := unitScale[sample.Unit]
+	if !ok || scale.canonical == sample.Unit {
+		return sample
+	}
+	sample.Value *= scale.factor
+	sample.Unit = scale.canonical
+	return sample
+}
@@ -0,0 +1,89 @@
+// Copyright (c) 2024-2026 Carsen Klock under MIT License
+package sinks
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/context-labs/mactop/v2/metrics/router"
+)
+
+// Influx writes samples as InfluxDB line protocol, either over UDP (fire
+// and forget, matching how node_exporter-adjacent tools ship line protocol
+// to telegraf) or HTTP (a single POST to /write per sample, for a direct
+// InfluxDB v1/v2 write endpoint). Exactly one of UDPAddr/HTTPURL should be
+// set; HTTPURL wins if both are.
+type Influx struct {
+	UDPAddr string
+	HTTPURL string
+	Token   string // optional, sent as "Authorization: Token <Token>" for HTTPURL
+
+	conn       net.Conn
+	httpClient *http.Client
+}
+
+// NewInfluxUDP returns an Influx sink that writes line protocol to addr
+// (host:port) over UDP.
+func NewInfluxUDP(addr string) (*Influx, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("sinks: dial influx udp %s: %w", addr, err)
+	}
+	return &Influx{UDPAddr: addr, conn: conn}, nil
+}
+
+// NewInfluxHTTP returns an Influx sink that POSTs line protocol to url
+// (e.g. "http://localhost:8086/api/v2/write?bucket=mactop&org=me").
+func NewInfluxHTTP(url, token string) *Influx {
+	return &Influx{HTTPURL: url, Token: token, httpClient: &http.Client{Timeout: 5 * time.Second}}
+}
+
+func (s *Influx) Write(sample router.Sample) error {
+	line := lineProtocol(sample)
+	if s.HTTPURL != "" {
+		req, err := http.NewRequest(http.MethodPost, s.HTTPURL, bytes.NewBufferString(line))
+		if err != nil {
+			return err
+		}
+		if s.Token != "" {
+			req.Header.Set("Authorization", "Token "+s.Token)
+		}
+		resp, err := s.httpClient.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode >= 300 {
+			return fmt.Errorf("sinks: influx http write returned %s", resp.Status)
+		}
+		return nil
+	}
+	if s.conn != nil {
+		_, err := s.conn.Write([]byte(line + "\n"))
+		return err
+	}
+	return fmt.Errorf("sinks: influx sink has neither HTTPURL nor an open UDP connection")
+}
+
+// lineProtocol renders sample as "measurement,tag=val,... value=N timestamp".
+func lineProtocol(sample router.Sample) string {
+	var sb strings.Builder
+	sb.WriteString(escapeLP(sample.Name))
+	for k, v := range sample.Tags {
+		sb.WriteByte(',')
+		sb.WriteString(escapeLP(k))
+		sb.WriteByte('=')
+		sb.WriteString(escapeLP(v))
+	}
+	sb.WriteString(fmt.Sprintf(" value=%g %d", sample.Value, sample.Timestamp.UnixNano()))
+	return sb.String()
+}
+
+func escapeLP(s string) string {
+	replacer := strings.NewReplacer(" ", "\\ ", ",", "\\,", "=", "\\=")
+	return replacer.Replace(s)
+}
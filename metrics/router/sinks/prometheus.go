@@ -0,0 +1,65 @@
+// Copyright (c) 2024-2026 Carsen Klock under MIT License
+package sinks
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/context-labs/mactop/v2/metrics/router"
+)
+
+// Prometheus fans router samples into a GaugeVec per metric name,
+// registered against Registry lazily on first sight of that name (since,
+// unlike mactop's hand-declared gauges in main.go, the router's metric
+// names and tag sets are only known at config/runtime, not compile time).
+type Prometheus struct {
+	Registry *prometheus.Registry
+
+	mu     sync.Mutex
+	gauges map[string]*prometheus.GaugeVec
+}
+
+// NewPrometheus returns a sink that registers gauges against registry as
+// new metric names appear.
+func NewPrometheus(registry *prometheus.Registry) *Prometheus {
+	return &Prometheus{Registry: registry, gauges: map[string]*prometheus.GaugeVec{}}
+}
+
+func (s *Prometheus) Write(sample router.Sample) error {
+	name := "mactop_router_" + sanitizeMetricName(sample.Name)
+	labelNames := make([]string, 0, len(sample.Tags))
+	for k := range sample.Tags {
+		labelNames = append(labelNames, k)
+	}
+	sort.Strings(labelNames)
+
+	s.mu.Lock()
+	gauge, ok := s.gauges[name]
+	if !ok {
+		gauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: name,
+			Help: "Routed metric " + sample.Name + " (see --metrics-config).",
+		}, labelNames)
+		if err := s.Registry.Register(gauge); err != nil {
+			s.mu.Unlock()
+			return err
+		}
+		s.gauges[name] = gauge
+	}
+	s.mu.Unlock()
+
+	gauge.With(prometheus.Labels(sample.Tags)).Set(sample.Value)
+	return nil
+}
+
+func sanitizeMetricName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' {
+			return r
+		}
+		return '_'
+	}, name)
+}
@@ -0,0 +1,59 @@
+// Copyright (c) 2024-2026 Carsen Klock under MIT License
+package sinks
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/context-labs/mactop/v2/metrics/router"
+)
+
+// StatsD writes samples as DogStatsD gauges over UDP ("name:value|g|#tag:val,...").
+// Plain StatsD has no tag syntax, so Tags are appended in the DogStatsD "|#"
+// form, which every StatsD-compatible collector mactop is likely to sit in
+// front of (Datadog agent, Telegraf, vector) understands; a server that
+// doesn't recognize it just ignores the trailing segment.
+type StatsD struct {
+	Addr string
+	conn net.Conn
+}
+
+// NewStatsD returns a StatsD sink that writes to addr (host:port) over UDP.
+func NewStatsD(addr string) (*StatsD, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("sinks: dial statsd %s: %w", addr, err)
+	}
+	return &StatsD{Addr: addr, conn: conn}, nil
+}
+
+func (s *StatsD) Write(sample router.Sample) error {
+	var sb strings.Builder
+	sb.WriteString(statsdName(sample.Name))
+	sb.WriteByte(':')
+	fmt.Fprintf(&sb, "%g", sample.Value)
+	sb.WriteString("|g")
+	if len(sample.Tags) > 0 {
+		sb.WriteString("|#")
+		first := true
+		for k, v := range sample.Tags {
+			if !first {
+				sb.WriteByte(',')
+			}
+			first = false
+			sb.WriteString(k)
+			sb.WriteByte(':')
+			sb.WriteString(v)
+		}
+	}
+	_, err := s.conn.Write([]byte(sb.String()))
+	return err
+}
+
+// statsdName replaces characters StatsD's "name:value|type" wire format
+// treats as delimiters, the same way lineProtocol's escapeLP guards Influx's.
+func statsdName(name string) string {
+	replacer := strings.NewReplacer(":", "_", "|", "_", "@", "_", " ", "_")
+	return "mactop." + replacer.Replace(name)
+}
@@ -0,0 +1,40 @@
+// Copyright (c) 2024-2026 Carsen Klock under MIT License
+package sinks
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/context-labs/mactop/v2/metrics/router"
+)
+
+// Stdout writes one JSON line per sample to W (os.Stdout in normal use;
+// swappable for tests).
+type Stdout struct {
+	W io.Writer
+}
+
+// NewStdout returns a Stdout sink writing to w.
+func NewStdout(w io.Writer) *Stdout {
+	return &Stdout{W: w}
+}
+
+type stdoutLine struct {
+	Metric    string            `json:"metric"`
+	Value     float64           `json:"value"`
+	Unit      string            `json:"unit,omitempty"`
+	Tags      map[string]string `json:"tags,omitempty"`
+	Timestamp int64             `json:"timestamp"`
+}
+
+func (s *Stdout) Write(sample router.Sample) error {
+	line := stdoutLine{
+		Metric:    sample.Name,
+		Value:     sample.Value,
+		Unit:      sample.Unit,
+		Tags:      sample.Tags,
+		Timestamp: sample.Timestamp.Unix(),
+	}
+	enc := json.NewEncoder(s.W)
+	return enc.Encode(line)
+}
@@ -0,0 +1,47 @@
+// Copyright (c) 2024-2026 Carsen Klock under MIT License
+package sinks
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+
+	"github.com/context-labs/mactop/v2/metrics/router"
+)
+
+// UnixSocket writes one JSON line per sample to a connected Unix domain
+// socket, for local consumers (a sidecar collector, a socket-activated
+// exporter) that don't want an HTTP round trip.
+type UnixSocket struct {
+	conn net.Conn
+}
+
+// NewUnixSocket dials path (e.g. ~/.mactop/metrics.sock); the peer must
+// already be listening.
+func NewUnixSocket(path string) (*UnixSocket, error) {
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("sinks: dial unix socket %s: %w", path, err)
+	}
+	return &UnixSocket{conn: conn}, nil
+}
+
+func (s *UnixSocket) Write(sample router.Sample) error {
+	line := stdoutLine{
+		Metric:    sample.Name,
+		Value:     sample.Value,
+		Unit:      sample.Unit,
+		Tags:      sample.Tags,
+		Timestamp: sample.Timestamp.Unix(),
+	}
+	data, err := json.Marshal(line)
+	if err != nil {
+		return err
+	}
+	_, err = s.conn.Write(append(data, '\n'))
+	return err
+}
+
+func (s *UnixSocket) Close() error {
+	return s.conn.Close()
+}
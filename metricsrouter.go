@@ -0,0 +1,104 @@
+// Copyright (c) 2024-2026 Carsen Klock under MIT License
+// metricsrouter.go - wires the metrics/router package into mactop: reads
+// --metrics-config, builds the requested sinks, and feeds each CPU sample
+// through the router from updateCPUUI the same way recordHistoryPoint and
+// evaluateAlerts already do.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/context-labs/mactop/v2/metrics/router"
+	"github.com/context-labs/mactop/v2/metrics/router/sinks"
+)
+
+var (
+	metricsConfigFlag string
+	statsdAddrFlag    string
+	metricsRouterInst *router.Router
+)
+
+// startMetricsRouter loads path as a router.Config and builds its sinks, then
+// (if --statsd-addr was also given) appends a plain StatsD sink with no
+// rules on top - the shorthand path for people who don't want to write a
+// JSON config just to get a StatsD sink. No-op (leaves metricsRouterInst
+// nil) if neither flag was given; a bad --metrics-config or --statsd-addr is
+// logged and skipped rather than stopping the rest of mactop.
+func startMetricsRouter(path string, promRegistry *prometheus.Registry) {
+	cfg := router.Config{}
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			stderrLogger.Printf("Failed to read --metrics-config %s: %v\n", path, err)
+		} else if err := json.Unmarshal(data, &cfg); err != nil {
+			stderrLogger.Printf("Failed to parse --metrics-config %s: %v\n", path, err)
+			cfg = router.Config{}
+		}
+	}
+	if path == "" && statsdAddrFlag == "" {
+		return
+	}
+
+	r := router.New(cfg)
+	for _, sc := range cfg.Sinks {
+		sink, err := buildSink(sc, promRegistry)
+		if err != nil {
+			stderrLogger.Printf("Failed to build metrics sink %q: %v\n", sc.Type, err)
+			continue
+		}
+		r.AddSink(sink)
+	}
+	if statsdAddrFlag != "" {
+		sink, err := sinks.NewStatsD(statsdAddrFlag)
+		if err != nil {
+			stderrLogger.Printf("Failed to build --statsd-addr sink: %v\n", err)
+		} else {
+			r.AddSink(sink)
+		}
+	}
+	metricsRouterInst = r
+}
+
+func buildSink(sc router.SinkConfig, promRegistry *prometheus.Registry) (router.Sink, error) {
+	switch sc.Type {
+	case "stdout":
+		return sinks.NewStdout(os.Stdout), nil
+	case "influx_udp":
+		return sinks.NewInfluxUDP(sc.Addr)
+	case "influx_http":
+		return sinks.NewInfluxHTTP(sc.URL, sc.Token), nil
+	case "unix_socket":
+		return sinks.NewUnixSocket(sc.Path)
+	case "prometheus":
+		return sinks.NewPrometheus(promRegistry), nil
+	case "statsd":
+		return sinks.NewStatsD(sc.Addr)
+	default:
+		return nil, fmt.Errorf("unknown sink type %q", sc.Type)
+	}
+}
+
+// routeSocSample fans a CPUMetrics sample out through metricsRouterInst, if
+// --metrics-config configured one. No-op otherwise.
+func routeSocSample(m CPUMetrics) {
+	if metricsRouterInst == nil {
+		return
+	}
+	now := time.Now()
+	samples := []router.Sample{
+		{Name: "cpu_power", Value: m.CPUW, Unit: "W", Tags: map[string]string{"domain": "cpu"}, Timestamp: now},
+		{Name: "gpu_power", Value: m.GPUW, Unit: "W", Tags: map[string]string{"domain": "gpu"}, Timestamp: now},
+		{Name: "ane_power", Value: m.ANEW, Unit: "W", Tags: map[string]string{"domain": "ane"}, Timestamp: now},
+		{Name: "dram_power", Value: m.DRAMW, Unit: "W", Tags: map[string]string{"domain": "dram"}, Timestamp: now},
+		{Name: "package_power", Value: m.PackageW, Unit: "W", Tags: map[string]string{"domain": "total"}, Timestamp: now},
+		{Name: "soc_temp", Value: m.SocTemp, Unit: "C", Timestamp: now},
+	}
+	for _, err := range metricsRouterInst.Route(samples) {
+		stderrLogger.Printf("metrics router sink error: %v\n", err)
+	}
+}
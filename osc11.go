@@ -0,0 +1,106 @@
+// Copyright (c) 2024-2026 Carsen Klock under MIT License
+// osc11.go - queries the terminal's actual background color via the OSC 11
+// control sequence (https://invisible-island.net/xterm/ctlseqs/ctlseqs.html),
+// so --colorscheme auto can pick a built-in palette that actually contrasts
+// with it instead of assuming a dark background like every other built-in
+// scheme in colorscheme.go does. Best-effort only: many terminals, and most
+// multiplexers/SSH paths without passthrough, never reply, in which case
+// queryOSC11Background just times out and the caller falls back to a
+// dark-background default.
+//
+// Must run before termbox-go takes over the terminal (see
+// resolveAutoColorScheme in colorscheme.go): once ui.Init() starts
+// termbox's background input goroutine, it's reading the same controlling
+// tty via SIGIO and will routinely win the race for the OSC 11 reply bytes
+// before a second reader here ever sees them.
+package main
+
+import (
+	"os"
+	"regexp"
+	"time"
+)
+
+// osc11ResponsePattern matches an OSC 11 reply's "rgb:RRRR/GGGG/BBBB" body
+// (each channel 1-4 hex digits); the reply may be BEL- or ST-terminated
+// depending on terminal, so the surrounding escape bytes aren't matched.
+var osc11ResponsePattern = regexp.MustCompile(`rgb:([0-9a-fA-F]{1,4})/([0-9a-fA-F]{1,4})/([0-9a-fA-F]{1,4})`)
+
+// parseOSC11Response extracts the background RGB from a raw OSC 11 reply,
+// scaling each channel up to the full 16-bit range regardless of how many
+// hex digits the terminal sent (some send 2 digits, some 4).
+func parseOSC11Response(resp string) (r, g, b uint16, ok bool) {
+	m := osc11ResponsePattern.FindStringSubmatch(resp)
+	if m == nil {
+		return 0, 0, 0, false
+	}
+	return scaleHexChannel(m[1]), scaleHexChannel(m[2]), scaleHexChannel(m[3]), true
+}
+
+// scaleHexChannel parses a 1-4 digit hex string and scales it to fill the
+// 16-bit range, the way a 2-digit "ff" means full-scale (0xffff) just as
+// much as a 4-digit "ffff" does.
+func scaleHexChannel(hex string) uint16 {
+	var v uint32
+	for _, c := range hex {
+		v <<= 4
+		switch {
+		case c >= '0' && c <= '9':
+			v |= uint32(c - '0')
+		case c >= 'a' && c <= 'f':
+			v |= uint32(c-'a') + 10
+		case c >= 'A' && c <= 'F':
+			v |= uint32(c-'A') + 10
+		}
+	}
+	bits := uint(len(hex) * 4)
+	for bits < 16 {
+		v = v<<bits | v
+		bits *= 2
+	}
+	return uint16(v >> (bits - 16))
+}
+
+// relativeLuminance computes WCAG relative luminance from 16-bit-per-channel
+// RGB, applying the standard piecewise sRGB gamma expansion before the
+// 0.2126/0.7152/0.0722 weighted sum.
+func relativeLuminance(r, g, b uint16) float64 {
+	expand := func(c uint16) float64 {
+		cs := float64(c) / 65535.0
+		if cs <= 0.03928 {
+			return cs / 12.92
+		}
+		return ((cs + 0.055) / 1.055) * ((cs + 0.055) / 1.055) * ((cs + 0.055) / 1.055)
+	}
+	return 0.2126*expand(r) + 0.7152*expand(g) + 0.0722*expand(b)
+}
+
+// isLightBackground applies the usual >0.5 relative-luminance split used to
+// decide whether light or dark foreground text reads better against bg.
+func isLightBackground(r, g, b uint16) bool {
+	return relativeLuminance(r, g, b) > 0.5
+}
+
+// queryOSC11Background sends the OSC 11 background-color query to tty and
+// waits up to timeout for a reply. ok is false on any read error, timeout,
+// or unparseable reply - the caller should treat that as "unknown" rather
+// than as light or dark.
+func queryOSC11Background(tty *os.File, timeout time.Duration) (r, g, b uint16, ok bool) {
+	if tty == nil {
+		return 0, 0, 0, false
+	}
+	if _, err := tty.WriteString("\x1b]11;?\x07"); err != nil {
+		return 0, 0, 0, false
+	}
+	if err := tty.SetReadDeadline(time.Now().Add(timeout)); err != nil {
+		return 0, 0, 0, false
+	}
+	defer tty.SetReadDeadline(time.Time{})
+
+	buf := make([]byte, 128)
+	n, err := tty.Read(buf)
+	if err != nil || n == 0 {
+		return 0, 0, 0, false
+	}
+	return parseOSC11Response(string(buf[:n]))
+}
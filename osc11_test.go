@@ -0,0 +1,79 @@
+// Copyright (c) 2024-2026 Carsen Klock under MIT License
+package main
+
+import "testing"
+
+func TestParseOSC11Response(t *testing.T) {
+	tests := []struct {
+		name   string
+		resp   string
+		wantR  uint16
+		wantG  uint16
+		wantB  uint16
+		wantOK bool
+	}{
+		{"BEL-terminated, 4-digit channels", "\x1b]11;rgb:1234/5678/9abc\x07", 0x1234, 0x5678, 0x9abc, true},
+		{"ST-terminated", "\x1b]11;rgb:ffff/ffff/ffff\x1b\\", 0xffff, 0xffff, 0xffff, true},
+		{"2-digit channels scale to full 16-bit range", "rgb:ff/80/00", 0xffff, 0x8080, 0x0000, true},
+		{"garbage, no OSC 11 body", "\x07", 0, 0, 0, false},
+		{"empty string", "", 0, 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, g, b, ok := parseOSC11Response(tt.resp)
+			if ok != tt.wantOK || r != tt.wantR || g != tt.wantG || b != tt.wantB {
+				t.Errorf("parseOSC11Response(%q) = (%#x, %#x, %#x, %v), want (%#x, %#x, %#x, %v)",
+					tt.resp, r, g, b, ok, tt.wantR, tt.wantG, tt.wantB, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestScaleHexChannel(t *testing.T) {
+	tests := []struct {
+		hex  string
+		want uint16
+	}{
+		{"f", 0xffff},
+		{"ff", 0xffff},
+		{"80", 0x8080},
+		{"00", 0x0000},
+		{"1234", 0x1234},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.hex, func(t *testing.T) {
+			if got := scaleHexChannel(tt.hex); got != tt.want {
+				t.Errorf("scaleHexChannel(%q) = %#x, want %#x", tt.hex, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsLightBackground(t *testing.T) {
+	tests := []struct {
+		name        string
+		r, g, b     uint16
+		wantIsLight bool
+	}{
+		{"white", 0xffff, 0xffff, 0xffff, true},
+		{"black", 0, 0, 0, false},
+		{"mid-grey dark side", 0x7fff, 0x7fff, 0x7fff, false},
+		{"typical light terminal bg", 0xeeee, 0xeeee, 0xeeee, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isLightBackground(tt.r, tt.g, tt.b); got != tt.wantIsLight {
+				t.Errorf("isLightBackground(%#x, %#x, %#x) = %v, want %v", tt.r, tt.g, tt.b, got, tt.wantIsLight)
+			}
+		})
+	}
+}
+
+func TestQueryOSC11BackgroundNilTTY(t *testing.T) {
+	if _, _, _, ok := queryOSC11Background(nil, 0); ok {
+		t.Error("queryOSC11Background(nil, ...) ok = true, want false")
+	}
+}
@@ -0,0 +1,23 @@
+//go:build darwin
+
+// Copyright (c) 2024-2026 Carsen Klock under MIT License
+package platform
+
+// darwinCollector is an unimplemented stub: every method returns
+// ErrUnsupported. It exists only so platform.New() compiles on macOS and the
+// package's cross-platform tests run there too - see package doc for why
+// mactop's own dashboard doesn't call into this package at all yet.
+type darwinCollector struct{}
+
+// New returns the platform.Collector for this OS.
+func New() Collector {
+	return &darwinCollector{}
+}
+
+func (c *darwinCollector) Init() error  { return nil }
+func (c *darwinCollector) Close() error { return nil }
+
+func (c *darwinCollector) CPUInfo() (CPUInfo, error)       { return CPUInfo{}, ErrUnsupported }
+func (c *darwinCollector) MemoryInfo() (MemoryInfo, error) { return MemoryInfo{}, ErrUnsupported }
+func (c *darwinCollector) DiskIO() (DiskIO, error)         { return DiskIO{}, ErrUnsupported }
+func (c *darwinCollector) NetIO() (NetIO, error)           { return NetIO{}, ErrUnsupported }
@@ -0,0 +1,239 @@
+//go:build linux
+
+// Copyright (c) 2024-2026 Carsen Klock under MIT License
+package platform
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// linuxCollector reads /proc directly (the way gopsutil and node_exporter's
+// procfs collector do) rather than shelling out, and layers cgroup v2
+// (falling back to v1) limits on top so a containerized view reflects the
+// container's quota/limit rather than the host's.
+type linuxCollector struct {
+	cgroupV2  bool
+	cgroupDir string
+}
+
+// New returns the platform.Collector for this OS.
+func New() Collector {
+	return &linuxCollector{}
+}
+
+func (c *linuxCollector) Init() error {
+	if _, err := os.Stat("/sys/fs/cgroup/cgroup.controllers"); err == nil {
+		c.cgroupV2 = true
+		c.cgroupDir = "/sys/fs/cgroup"
+	} else {
+		c.cgroupDir = cgroupV1Dir()
+	}
+	return nil
+}
+
+func (c *linuxCollector) Close() error { return nil }
+
+func (c *linuxCollector) CPUInfo() (CPUInfo, error) {
+	data, err := os.ReadFile("/proc/cpuinfo")
+	if err != nil {
+		return CPUInfo{}, err
+	}
+	info := CPUInfo{}
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.HasPrefix(line, "model name") {
+			if idx := strings.Index(line, ":"); idx != -1 && info.ModelName == "" {
+				info.ModelName = strings.TrimSpace(line[idx+1:])
+			}
+		}
+		if strings.HasPrefix(line, "processor") {
+			info.CoreCount++
+		}
+	}
+	info.CgroupQuota = c.cpuQuota()
+	return info, nil
+}
+
+// cpuQuota reads the cgroup CPU quota as a core count (e.g. 2.5 for "250ms
+// per 100ms period"), or 0 if the cgroup is unconstrained or unreadable.
+func (c *linuxCollector) cpuQuota() float64 {
+	if c.cgroupDir == "" {
+		return 0
+	}
+	if c.cgroupV2 {
+		data, err := os.ReadFile(c.cgroupDir + "/cpu.max")
+		if err != nil {
+			return 0
+		}
+		fields := strings.Fields(string(data))
+		if len(fields) != 2 || fields[0] == "max" {
+			return 0
+		}
+		quota, err1 := strconv.ParseFloat(fields[0], 64)
+		period, err2 := strconv.ParseFloat(fields[1], 64)
+		if err1 != nil || err2 != nil || period == 0 {
+			return 0
+		}
+		return quota / period
+	}
+	quotaStr, err1 := readTrimmed(c.cgroupDir + "/cpu/cpu.cfs_quota_us")
+	periodStr, err2 := readTrimmed(c.cgroupDir + "/cpu/cpu.cfs_period_us")
+	if err1 != nil || err2 != nil {
+		return 0
+	}
+	quota, err1 := strconv.ParseFloat(quotaStr, 64)
+	period, err2 := strconv.ParseFloat(periodStr, 64)
+	if err1 != nil || err2 != nil || quota <= 0 || period == 0 {
+		return 0
+	}
+	return quota / period
+}
+
+func (c *linuxCollector) MemoryInfo() (MemoryInfo, error) {
+	f, err := os.Open("/proc/meminfo")
+	if err != nil {
+		return MemoryInfo{}, err
+	}
+	defer f.Close()
+
+	fields := map[string]uint64{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		parts := strings.Fields(scanner.Text())
+		if len(parts) < 2 {
+			continue
+		}
+		key := strings.TrimSuffix(parts[0], ":")
+		val, err := strconv.ParseUint(parts[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		fields[key] = val * 1024 // /proc/meminfo is in KiB
+	}
+
+	info := MemoryInfo{
+		Total:     fields["MemTotal"],
+		Available: fields["MemAvailable"],
+		SwapTotal: fields["SwapTotal"],
+	}
+	info.Used = info.Total - info.Available
+	info.SwapUsed = info.SwapTotal - fields["SwapFree"]
+	info.CgroupMax = c.memoryMax()
+	return info, nil
+}
+
+// memoryMax reads the cgroup memory limit, or 0 if unconstrained/unreadable.
+func (c *linuxCollector) memoryMax() uint64 {
+	if c.cgroupDir == "" {
+		return 0
+	}
+	path := c.cgroupDir + "/memory.max"
+	if !c.cgroupV2 {
+		path = c.cgroupDir + "/memory/memory.limit_in_bytes"
+	}
+	s, err := readTrimmed(path)
+	if err != nil || s == "max" {
+		return 0
+	}
+	v, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return v
+}
+
+func (c *linuxCollector) DiskIO() (DiskIO, error) {
+	f, err := os.Open("/proc/diskstats")
+	if err != nil {
+		return DiskIO{}, err
+	}
+	defer f.Close()
+
+	var result DiskIO
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		// Field layout: major minor name reads ... sectors_read ... writes ... sectors_written ...
+		if len(fields) < 10 {
+			continue
+		}
+		name := fields[2]
+		if strings.HasPrefix(name, "loop") || strings.HasPrefix(name, "ram") {
+			continue
+		}
+		sectorsRead, err1 := strconv.ParseUint(fields[5], 10, 64)
+		sectorsWritten, err2 := strconv.ParseUint(fields[9], 10, 64)
+		if err1 == nil {
+			result.ReadBytes += sectorsRead * 512
+		}
+		if err2 == nil {
+			result.WriteBytes += sectorsWritten * 512
+		}
+	}
+	return result, nil
+}
+
+func (c *linuxCollector) NetIO() (NetIO, error) {
+	f, err := os.Open("/proc/net/dev")
+	if err != nil {
+		return NetIO{}, err
+	}
+	defer f.Close()
+
+	var result NetIO
+	scanner := bufio.NewScanner(f)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		if lineNum <= 2 {
+			continue // header lines
+		}
+		line := scanner.Text()
+		idx := strings.Index(line, ":")
+		if idx == -1 {
+			continue
+		}
+		iface := strings.TrimSpace(line[:idx])
+		if iface == "lo" {
+			continue
+		}
+		fields := strings.Fields(line[idx+1:])
+		if len(fields) < 9 {
+			continue
+		}
+		rx, err1 := strconv.ParseUint(fields[0], 10, 64)
+		tx, err2 := strconv.ParseUint(fields[8], 10, 64)
+		if err1 == nil {
+			result.RxBytes += rx
+		}
+		if err2 == nil {
+			result.TxBytes += tx
+		}
+	}
+	return result, nil
+}
+
+func readTrimmed(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// cgroupV1Dir finds this process's cgroup v1 mount root by reading
+// /proc/self/cgroup; returns "" if not running under cgroup v1.
+func cgroupV1Dir() string {
+	data, err := os.ReadFile("/proc/self/cgroup")
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.Contains(line, "cpu,cpuacct") || strings.Contains(line, ":cpu:") {
+			return "/sys/fs/cgroup"
+		}
+	}
+	return ""
+}
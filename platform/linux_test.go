@@ -0,0 +1,120 @@
+// Copyright (c) 2024-2026 Carsen Klock under MIT License
+package platform
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLinuxCollectorCPUQuotaV2(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "cpu.max"), []byte("250000 100000\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	c := &linuxCollector{cgroupV2: true, cgroupDir: dir}
+	if got, want := c.cpuQuota(), 2.5; got != want {
+		t.Errorf("cpuQuota() = %v, want %v", got, want)
+	}
+}
+
+func TestLinuxCollectorCPUQuotaV2Unlimited(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "cpu.max"), []byte("max 100000\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	c := &linuxCollector{cgroupV2: true, cgroupDir: dir}
+	if got := c.cpuQuota(); got != 0 {
+		t.Errorf("cpuQuota() = %v, want 0 for an unconstrained (\"max\") quota", got)
+	}
+}
+
+func TestLinuxCollectorCPUQuotaV1(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "cpu"), 0o755); err != nil {
+		t.Fatalf("Mkdir() error = %v", err)
+	}
+	os.WriteFile(filepath.Join(dir, "cpu", "cpu.cfs_quota_us"), []byte("150000\n"), 0o644)
+	os.WriteFile(filepath.Join(dir, "cpu", "cpu.cfs_period_us"), []byte("100000\n"), 0o644)
+
+	c := &linuxCollector{cgroupV2: false, cgroupDir: dir}
+	if got, want := c.cpuQuota(), 1.5; got != want {
+		t.Errorf("cpuQuota() = %v, want %v", got, want)
+	}
+}
+
+func TestLinuxCollectorCPUQuotaV1Unlimited(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "cpu"), 0o755); err != nil {
+		t.Fatalf("Mkdir() error = %v", err)
+	}
+	os.WriteFile(filepath.Join(dir, "cpu", "cpu.cfs_quota_us"), []byte("-1\n"), 0o644)
+	os.WriteFile(filepath.Join(dir, "cpu", "cpu.cfs_period_us"), []byte("100000\n"), 0o644)
+
+	c := &linuxCollector{cgroupV2: false, cgroupDir: dir}
+	if got := c.cpuQuota(); got != 0 {
+		t.Errorf("cpuQuota() = %v, want 0 for a -1 (\"unconstrained\") quota", got)
+	}
+}
+
+func TestLinuxCollectorCPUQuotaNoCgroupDir(t *testing.T) {
+	c := &linuxCollector{}
+	if got := c.cpuQuota(); got != 0 {
+		t.Errorf("cpuQuota() = %v, want 0 when cgroupDir is unset", got)
+	}
+}
+
+func TestLinuxCollectorMemoryMaxV2(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "memory.max"), []byte("1073741824\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	c := &linuxCollector{cgroupV2: true, cgroupDir: dir}
+	if got, want := c.memoryMax(), uint64(1073741824); got != want {
+		t.Errorf("memoryMax() = %v, want %v", got, want)
+	}
+}
+
+func TestLinuxCollectorMemoryMaxV2Unlimited(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "memory.max"), []byte("max\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	c := &linuxCollector{cgroupV2: true, cgroupDir: dir}
+	if got := c.memoryMax(); got != 0 {
+		t.Errorf("memoryMax() = %v, want 0 for an unconstrained (\"max\") limit", got)
+	}
+}
+
+func TestLinuxCollectorMemoryMaxV1(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "memory"), 0o755); err != nil {
+		t.Fatalf("Mkdir() error = %v", err)
+	}
+	os.WriteFile(filepath.Join(dir, "memory", "memory.limit_in_bytes"), []byte("536870912\n"), 0o644)
+
+	c := &linuxCollector{cgroupV2: false, cgroupDir: dir}
+	if got, want := c.memoryMax(), uint64(536870912); got != want {
+		t.Errorf("memoryMax() = %v, want %v", got, want)
+	}
+}
+
+func TestReadTrimmed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "value")
+	if err := os.WriteFile(path, []byte("  42\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	got, err := readTrimmed(path)
+	if err != nil {
+		t.Fatalf("readTrimmed() error = %v", err)
+	}
+	if want := "42"; got != want {
+		t.Errorf("readTrimmed() = %q, want %q", got, want)
+	}
+}
+
+func TestReadTrimmedMissingFile(t *testing.T) {
+	if _, err := readTrimmed(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("readTrimmed() error = nil, want an error for a missing file")
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2024-2026 Carsen Klock under MIT License
+// Package platform is a build-time-selected Collector for CPU/memory/disk/
+// network counters, with a real /proc- and cgroup-based implementation for
+// Linux (linux.go) so mactop-style dashboards can run on Asahi Linux and
+// inside Docker/Kubernetes containers on Apple silicon.
+//
+// darwin.go's Collector is a stub: nothing in the main package imports this
+// package yet. mactop's own dashboard still samples Apple Silicon directly
+// through the cgo/IOReport path and main.go's getCPUInfo/getMemoryMetrics/
+// getCoreCounts/getGPUCores/getSOCInfo, which predate this package and have
+// no portable equivalent to swap in without reworking those call sites.
+// darwin.go exists only so package platform builds and its tests run on
+// both OSes; wiring main.go onto Collector is follow-up work, not done here.
+package platform
+
+import "errors"
+
+// ErrUnsupported is returned by a Collector method with no implementation on
+// the current platform, rather than panicking or exiting.
+var ErrUnsupported = errors.New("platform: not supported on this OS")
+
+// CPUInfo is a point-in-time CPU snapshot.
+type CPUInfo struct {
+	ModelName   string
+	CoreCount   int
+	PerCorePct  []float64
+	CgroupQuota float64 // cgroup CPU quota as a core count, 0 if unconstrained
+}
+
+// MemoryInfo is a point-in-time memory snapshot, in bytes.
+type MemoryInfo struct {
+	Total     uint64
+	Used      uint64
+	Available uint64
+	SwapTotal uint64
+	SwapUsed  uint64
+	CgroupMax uint64 // cgroup memory.max, 0 if unconstrained
+}
+
+// DiskIO is cumulative disk read/write counters since boot, as reported by
+// the OS; callers compute rates by differencing successive samples.
+type DiskIO struct {
+	ReadBytes  uint64
+	WriteBytes uint64
+}
+
+// NetIO is cumulative network counters since boot, summed across interfaces.
+type NetIO struct {
+	RxBytes uint64
+	TxBytes uint64
+}
+
+// Collector samples OS-level metrics. Implementations should be cheap to
+// call every tick; anything requiring real I/O fan-out belongs in a
+// devices.Collector plugin instead.
+type Collector interface {
+	Init() error
+	CPUInfo() (CPUInfo, error)
+	MemoryInfo() (MemoryInfo, error)
+	DiskIO() (DiskIO, error)
+	NetIO() (NetIO, error)
+	Close() error
+}
@@ -0,0 +1,160 @@
+// Copyright (c) 2024-2026 Carsen Klock under MIT License
+// plugin.go - loads third-party mactop plugins (*.so files built with
+// `go build -buildmode=plugin`) from ~/.config/mactop/plugins, mirroring
+// gotop's --extensions mechanism. Each plugin exports a Register func
+// matching pluginapi.RegisterFunc; see pluginapi.Registry for the ABI and
+// examples/plugins/tempsensor for a worked example.
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"plugin"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/context-labs/mactop/v2/devices"
+	"github.com/context-labs/mactop/v2/pluginapi"
+)
+
+// pluginTickChan broadcasts the same collection-tick a plugin would otherwise
+// need its own timer for; fed non-blockingly from the render loop's ticker.
+var pluginTickChan = make(chan time.Time, 1)
+
+// registryImpl is the concrete pluginapi.Registry handed to every plugin's
+// Register func.
+type registryImpl struct{}
+
+func (registryImpl) RegisterWidget(name string, widget interface{}) {
+	registerWidget(name, widget)
+}
+
+func (registryImpl) RegisterCollector(c prometheus.Collector) error {
+	return prometheus.Register(c)
+}
+
+func (registryImpl) RegisterKeybinding(key string, handler func()) {
+	pluginKeybindings[key] = handler
+}
+
+func (registryImpl) RegisterLayoutPreset(name, spec string) error {
+	return registerPluginLayoutPreset(name, spec)
+}
+
+func (registryImpl) Tick() <-chan time.Time {
+	return pluginTickChan
+}
+
+func (registryImpl) RegisterDevice(c devices.Collector) {
+	devices.Register(c)
+}
+
+// pluginKeybindings holds keys registered by plugins, consulted by the main
+// event loop's keyboard switch after its own built-in bindings miss.
+var pluginKeybindings = map[string]func(){}
+
+// deviceMetric is the single GaugeVec every devices.Collector's Sample
+// output is published through, labeled by device/metric name rather than
+// minted per-collector, since plugins register at runtime and there's no
+// fixed metric name to declare ahead of time.
+var deviceMetric = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "mactop_device_metric",
+		Help: "Values sampled from a pluggable devices.Collector, labeled by device and metric name.",
+	},
+	[]string{"device", "metric"},
+)
+
+// pluginDirs are checked in order for third-party plugin .so files, mirroring
+// layoutConfPaths' two-path convention.
+func pluginDirs(homeDir string) []string {
+	return []string{
+		filepath.Join(homeDir, ".config", "mactop", "plugins"),
+		filepath.Join(homeDir, ".mactop", "plugins"),
+	}
+}
+
+// loadPlugins scans the plugin directories for *.so files and calls each
+// one's Register func with a shared Registry. Load failures are logged and
+// skipped rather than fatal, since a broken plugin shouldn't take down mactop.
+// Any devices.Collector a plugin registers is started sampling immediately.
+func loadPlugins() {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return
+	}
+	reg := registryImpl{}
+	loaded := false
+	for _, dir := range pluginDirs(homeDir) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if entry.IsDir() || filepath.Ext(entry.Name()) != ".so" {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+			p, err := plugin.Open(path)
+			if err != nil {
+				stderrLogger.Printf("Failed to load plugin %s: %v\n", path, err)
+				continue
+			}
+			sym, err := p.Lookup(pluginapi.RegisterFunc)
+			if err != nil {
+				stderrLogger.Printf("Plugin %s has no %s func: %v\n", path, pluginapi.RegisterFunc, err)
+				continue
+			}
+			register, ok := sym.(func(pluginapi.Registry))
+			if !ok {
+				stderrLogger.Printf("Plugin %s's %s has the wrong signature\n", path, pluginapi.RegisterFunc)
+				continue
+			}
+			register(reg)
+			stderrLogger.Printf("Loaded plugin %s\n", path)
+			loaded = true
+		}
+	}
+	if loaded {
+		prometheus.MustRegister(deviceMetric)
+		go runDeviceCollectors()
+	}
+}
+
+// runDeviceCollectors inits every plugin-registered devices.Collector, then
+// samples each of them on pluginTickChan, publishing results to deviceMetric.
+// A collector whose Init fails is logged and left out of the sampling loop.
+func runDeviceCollectors() {
+	var active []devices.Collector
+	for _, c := range devices.All() {
+		if err := c.Init(); err != nil {
+			stderrLogger.Printf("Device collector %s failed to init: %v\n", c.Name(), err)
+			continue
+		}
+		active = append(active, c)
+	}
+	if len(active) == 0 {
+		return
+	}
+	defer func() {
+		for _, c := range active {
+			c.Close()
+		}
+	}()
+
+	interval := time.Duration(updateInterval) * time.Millisecond
+	for range pluginTickChan {
+		for _, c := range active {
+			values, err := c.Sample(context.Background(), interval)
+			if err != nil {
+				stderrLogger.Printf("Device collector %s sample failed: %v\n", c.Name(), err)
+				continue
+			}
+			for metric, v := range values {
+				deviceMetric.With(prometheus.Labels{"device": c.Name(), "metric": metric}).Set(v)
+			}
+		}
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright (c) 2024-2026 Carsen Klock under MIT License
+// Package pluginapi is the ABI surface third-party mactop plugins build
+// against. It is intentionally the only mactop package a plugin imports:
+// main can't be imported, and internal/* is off-limits to code outside this
+// module, so Registry is how a plugin's Register func reaches back into the
+// running process.
+package pluginapi
+
+import (
+	"time"
+
+	"github.com/context-labs/mactop/v2/devices"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Registry is passed to a plugin's Register func at load time. Each method
+// is additive: plugins are not expected to remove or replace mactop's own
+// widgets, collectors, or keybindings.
+type Registry interface {
+	// RegisterWidget makes a termui Drawable available to the layout DSL
+	// under name, the same way the built-in widgets are (see layoutdsl.go's
+	// registerWidget). Passed as interface{} since requiring plugins to
+	// import termui themselves would pin their termui version to mactop's.
+	RegisterWidget(name string, widget interface{})
+
+	// RegisterCollector adds a Prometheus collector to the default registry
+	// that --exporter=prometheus and --prometheus serve.
+	RegisterCollector(c prometheus.Collector) error
+
+	// RegisterDevice adds a devices.Collector, sampled generically on
+	// mactop's own update interval and exposed as
+	// mactop_device_<name>_<metric> gauges, for samplers that produce named
+	// values rather than owning their own prometheus.Collector.
+	RegisterDevice(c devices.Collector)
+
+	// RegisterKeybinding adds a global keypress handler, invoked whenever
+	// the TUI event loop sees that key and no built-in binding claims it.
+	RegisterKeybinding(key string, handler func())
+
+	// RegisterLayoutPreset adds a named layout, in the same row/column DSL
+	// ~/.config/mactop/layout.conf and --layout use (see layoutdsl.go), so a
+	// plugin that also registers widgets can ship a preset arranging them
+	// without requiring the user to hand-write a layout.conf section. Takes
+	// effect alongside the built-in presets for --layout and the 'l' key's
+	// cycle order; a name matching a built-in or layout.conf section is
+	// rejected so a plugin can't silently override the user's own config.
+	RegisterLayoutPreset(name, spec string) error
+
+	// Tick returns the shared metrics-collection ticker, so a plugin's own
+	// sampling can stay in lockstep with mactop's update rate instead of
+	// running its own timer.
+	Tick() <-chan time.Time
+}
+
+// RegisterFunc is the well-known symbol name a plugin .so must export:
+//
+//	func Register(r pluginapi.Registry) {...}
+const RegisterFunc = "Register"
@@ -0,0 +1,127 @@
+// Copyright (c) 2024-2026 Carsen Klock under MIT License
+// processenergy.go - cumulative per-process ENERGY, layered on top of
+// attributeProcessPower's instantaneous PowerWatts estimate the same way
+// energy.go layers mactop_energy_joules_total on top of mactop_power_watts:
+// each tick's PowerWatts * elapsed seconds is added to a running total. The
+// total is keyed by (pid, started) rather than bare pid, since ps aux
+// reuses pids and a reused pid should start its ENERGY column back at zero
+// rather than inheriting a dead process's total; entries for a (pid,
+// started) pair not seen this tick are dropped, since "missing from ps aux"
+// is the only exit signal mactop has.
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	w "github.com/gizak/termui/v3/widgets"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// processEnergyTopN bounds both the "power" layout's bar chart and the
+// top-N mactop_process_energy_joules_total export, the same way
+// processPowerTopN already bounds mactop_process_power_watts.
+const processEnergyTopN = 10
+
+type processEnergyKey struct {
+	pid     int
+	started string
+}
+
+var (
+	processEnergyMu       sync.Mutex
+	processEnergyMJ       = map[processEnergyKey]float64{}
+	lastProcessEnergyTick time.Time
+
+	energyTopWidget = newEnergyTopWidget()
+)
+
+func newEnergyTopWidget() *w.BarChart {
+	bc := w.NewBarChart()
+	bc.Title = "Top Energy (J)"
+	bc.BarWidth = 7
+	bc.BarGap = 1
+	return bc
+}
+
+// accumulateProcessEnergy turns this tick's PowerWatts (already apportioned
+// by attributeProcessPower) into each process's running ENERGY total, in
+// mJ. Skips the first call after startup, since there's no prior tick to
+// measure elapsed time against.
+func accumulateProcessEnergy(processes []ProcessMetrics) {
+	now := time.Now()
+	processEnergyMu.Lock()
+	if lastProcessEnergyTick.IsZero() {
+		lastProcessEnergyTick = now
+		processEnergyMu.Unlock()
+		return
+	}
+	elapsed := now.Sub(lastProcessEnergyTick).Seconds()
+	lastProcessEnergyTick = now
+
+	seen := make(map[processEnergyKey]bool, len(processes))
+	for i := range processes {
+		key := processEnergyKey{pid: processes[i].PID, started: processes[i].Started}
+		seen[key] = true
+		processEnergyMJ[key] += processes[i].PowerWatts * elapsed * 1000
+		processes[i].EnergyMJ = processEnergyMJ[key]
+	}
+	for key := range processEnergyMJ {
+		if !seen[key] {
+			delete(processEnergyMJ, key)
+		}
+	}
+	processEnergyMu.Unlock()
+
+	publishProcessEnergy(processes)
+}
+
+// publishProcessEnergy refreshes the "power" layout's top-N bar chart, and
+// (unless --attribute-pid/--attribute-cmd is active, in which case that
+// flag already owns mactop_process_energy_joules_total for its single-
+// process apportionment - see energy.go) exports the same top-N as
+// mactop_process_energy_joules_total.
+func publishProcessEnergy(processes []ProcessMetrics) {
+	top := append([]ProcessMetrics(nil), processes...)
+	sort.Slice(top, func(i, j int) bool { return top[i].EnergyMJ > top[j].EnergyMJ })
+	if len(top) > processEnergyTopN {
+		top = top[:processEnergyTopN]
+	}
+	updateEnergyTopWidget(top)
+
+	if attributePID != 0 || attributeCmdRe != nil {
+		return
+	}
+	processEnergyJoulesTotal.Reset()
+	for _, p := range top {
+		processEnergyJoulesTotal.With(prometheus.Labels{
+			"pid":  strconv.Itoa(p.PID),
+			"comm": p.Command,
+		}).Set(p.EnergyMJ / 1000)
+	}
+}
+
+func updateEnergyTopWidget(top []ProcessMetrics) {
+	data := make([]float64, len(top))
+	labels := make([]string, len(top))
+	for i, p := range top {
+		data[i] = p.EnergyMJ / 1000
+		labels[i] = truncateWithEllipsis(p.Command, 8)
+	}
+	energyTopWidget.Data = data
+	energyTopWidget.Labels = labels
+}
+
+// formatEnergy renders a cumulative mJ total the same way formatMemorySize
+// scales kb into K/M/G, so the ENERGY column stays readable once a
+// long-running process has accumulated kilojoules.
+func formatEnergy(mj float64) string {
+	j := mj / 1000
+	if j >= 1000 {
+		return fmt.Sprintf("%.1fkJ", j/1000)
+	}
+	return fmt.Sprintf("%.0fJ", j)
+}
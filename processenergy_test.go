@@ -0,0 +1,124 @@
+// Copyright (c) 2024-2026 Carsen Klock under MIT License
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseProcessEnergyImpact(t *testing.T) {
+	const out = "Processes: 412 total\n" +
+		"\n" +
+		"PID    POWER\n" +
+		"1234   8.5\n" +
+		"5678   1.2\n" +
+		"not-a-pid x\n"
+
+	scores := parseProcessEnergyImpact(out)
+	if len(scores) != 2 {
+		t.Fatalf("parseProcessEnergyImpact() returned %d scores, want 2: %v", len(scores), scores)
+	}
+	if scores[1234] != 8.5 {
+		t.Errorf("scores[1234] = %v, want 8.5", scores[1234])
+	}
+	if scores[5678] != 1.2 {
+		t.Errorf("scores[5678] = %v, want 1.2", scores[5678])
+	}
+}
+
+func TestParseProcessEnergyImpactMissingHeaderReturnsNil(t *testing.T) {
+	if got := parseProcessEnergyImpact("Processes: 412 total\n1234 8.5\n"); got != nil {
+		t.Errorf("parseProcessEnergyImpact() = %v, want nil when the PID/POWER header is never seen", got)
+	}
+}
+
+func TestParseProcessEnergyImpactEmptyTableReturnsNil(t *testing.T) {
+	if got := parseProcessEnergyImpact("PID    POWER\n"); got != nil {
+		t.Errorf("parseProcessEnergyImpact() = %v, want nil when no rows parse", got)
+	}
+}
+
+func TestAttributeProcessPowerFallsBackToCPUProportionalSplit(t *testing.T) {
+	origWatts := lastPackageWatts
+	defer func() { lastPackageWatts = origWatts }()
+	lastPackageWatts = 10.0
+
+	processes := []ProcessMetrics{
+		{PID: 1, CPU: 75},
+		{PID: 2, CPU: 25},
+	}
+	attributeProcessPower(processes)
+
+	if got, want := processes[0].PowerWatts, 7.5; got != want {
+		t.Errorf("processes[0].PowerWatts = %v, want %v", got, want)
+	}
+	if got, want := processes[1].PowerWatts, 2.5; got != want {
+		t.Errorf("processes[1].PowerWatts = %v, want %v", got, want)
+	}
+}
+
+func TestAttributeProcessPowerNoPackageWattsLeavesPowerUnset(t *testing.T) {
+	origWatts := lastPackageWatts
+	defer func() { lastPackageWatts = origWatts }()
+	lastPackageWatts = 0
+
+	processes := []ProcessMetrics{{PID: 1, CPU: 50}}
+	attributeProcessPower(processes)
+
+	if processes[0].PowerWatts != 0 {
+		t.Errorf("processes[0].PowerWatts = %v, want 0 when lastPackageWatts <= 0", processes[0].PowerWatts)
+	}
+}
+
+func TestAccumulateProcessEnergyAccumulatesAndEvicts(t *testing.T) {
+	origMJ := processEnergyMJ
+	origTick := lastProcessEnergyTick
+	defer func() {
+		processEnergyMJ = origMJ
+		lastProcessEnergyTick = origTick
+	}()
+
+	processEnergyMJ = map[processEnergyKey]float64{}
+	lastProcessEnergyTick = time.Now().Add(-2 * time.Second)
+
+	processes := []ProcessMetrics{{PID: 1, Started: "12:00", PowerWatts: 2.0}}
+	accumulateProcessEnergy(processes)
+
+	// ~2s elapsed * 2W * 1000 (mJ/J) = ~4000mJ; allow slack for the real
+	// time.Now() calls inside accumulateProcessEnergy vs. this test's clock.
+	if got := processes[0].EnergyMJ; got < 3000 || got > 5000 {
+		t.Errorf("processes[0].EnergyMJ = %v, want roughly 4000 (2s * 2W * 1000)", got)
+	}
+	key := processEnergyKey{pid: 1, started: "12:00"}
+	if _, ok := processEnergyMJ[key]; !ok {
+		t.Fatal("processEnergyMJ missing entry for the process just accumulated")
+	}
+
+	// Process 1 no longer present this tick: its running total should be
+	// evicted rather than carried forward to a future reused pid.
+	accumulateProcessEnergy([]ProcessMetrics{{PID: 2, Started: "12:01", PowerWatts: 1.0}})
+	if _, ok := processEnergyMJ[key]; ok {
+		t.Error("processEnergyMJ still has the pid-1 entry after it dropped out of the process list")
+	}
+}
+
+func TestFormatEnergy(t *testing.T) {
+	tests := []struct {
+		mj   float64
+		want string
+	}{
+		{500, "0J"},
+		{999, "1J"},
+		{1500, "2J"},
+		{999_000, "999J"},
+		{1_000_000, "1.0kJ"},
+		{2_500_000, "2.5kJ"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := formatEnergy(tt.mj); got != tt.want {
+				t.Errorf("formatEnergy(%v) = %q, want %q", tt.mj, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright (c) 2024-2026 Carsen Klock under MIT License
+// processsource.go - pluggable backends for getProcessList. The default,
+// psExecProcessSource, shells out to `ps aux`, which requires a `ps` binary
+// on PATH; gopsutilProcessSource is a pure-Go alternative (no exec, no cgo)
+// for environments that don't ship one, e.g. a CGO_ENABLED=0 Docker image
+// built for --prometheus-only/--exporter-only use. Selected via
+// --process-source. This does not make the rest of mactop portable off
+// Darwin - GetCPUUsage and the IOReport power sampler still depend on
+// Mach-specific C (via cgo or purego) regardless of which ProcessSource is
+// active; this switch only covers where the process list comes from.
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"os/exec"
+
+	"github.com/shirou/gopsutil/v4/process"
+)
+
+// ProcessSource is how getProcessList gathers OS process info.
+type ProcessSource interface {
+	List() ([]ProcessMetrics, error)
+}
+
+// processSource is the active backend; default preserves mactop's
+// longstanding behavior. Overridden by --process-source=gopsutil.
+var processSource ProcessSource = psExecProcessSource{}
+
+func selectProcessSource(name string) error {
+	switch name {
+	case "", "ps":
+		processSource = psExecProcessSource{}
+	case "gopsutil":
+		processSource = gopsutilProcessSource{}
+	default:
+		return fmt.Errorf("unknown process source %q (want ps or gopsutil)", name)
+	}
+	return nil
+}
+
+// psExecProcessSource is the original getProcessList body, parsing `ps aux`
+// column output.
+type psExecProcessSource struct{}
+
+func (psExecProcessSource) List() ([]ProcessMetrics, error) {
+	cmd := exec.Command("ps", "aux")
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+	numCPU := float64(runtime.NumCPU())
+	raw := processCPUModeIsRaw()
+	processes := []ProcessMetrics{}
+	lines := strings.Split(string(output), "\n")
+	for _, line := range lines[1:] {
+		if line == "" {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 11 {
+			continue
+		}
+		cpu, _ := strconv.ParseFloat(replaceCommas(fields[2]), 64)
+		if !raw {
+			cpu = cpu / numCPU
+		}
+		mem, _ := strconv.ParseFloat(replaceCommas(fields[3]), 64)
+		vsz, _ := strconv.ParseInt(fields[4], 10, 64)
+		rss, _ := strconv.ParseInt(fields[5], 10, 64)
+		pid, _ := strconv.Atoi(fields[1])
+		command := filepath.Base(fields[10])
+		processes = append(processes, ProcessMetrics{User: fields[0], PID: pid, CPU: cpu, Memory: mem, VSZ: vsz, RSS: rss, TTY: fields[6], State: fields[7], Started: fields[8], Time: fields[9], Command: command})
+	}
+	sort.Slice(processes, func(i, j int) bool {
+		return processes[i].CPU > processes[j].CPU
+	})
+	return processes, nil
+}
+
+// gopsutilProcessSource reimplements the same rows using
+// github.com/shirou/gopsutil/v4/process instead of exec'ing ps, at the cost
+// of somewhat coarser TTY/State/Time fields than ps aux reports natively.
+type gopsutilProcessSource struct{}
+
+func (gopsutilProcessSource) List() ([]ProcessMetrics, error) {
+	procs, err := process.Processes()
+	if err != nil {
+		return nil, err
+	}
+	numCPU := float64(runtime.NumCPU())
+	raw := processCPUModeIsRaw()
+	processes := make([]ProcessMetrics, 0, len(procs))
+	for _, p := range procs {
+		cpu, err := p.CPUPercent()
+		if err != nil {
+			continue
+		}
+		if !raw {
+			cpu = cpu / numCPU
+		}
+		memPct, _ := p.MemoryPercent()
+		username, _ := p.Username()
+		name, _ := p.Name()
+		var vsz, rss int64
+		if info, err := p.MemoryInfo(); err == nil && info != nil {
+			vsz = int64(info.VMS) / 1024
+			rss = int64(info.RSS) / 1024
+		}
+		started := ""
+		if createMS, err := p.CreateTime(); err == nil {
+			started = time.UnixMilli(createMS).Format("15:04")
+		}
+		state := ""
+		if statuses, err := p.Status(); err == nil {
+			state = strings.Join(statuses, "")
+		}
+		cpuTime := ""
+		if times, err := p.Times(); err == nil && times != nil {
+			total := time.Duration((times.User + times.System) * float64(time.Second))
+			cpuTime = fmt.Sprintf("%d:%02d.%02d", int(total.Minutes()), int(total.Seconds())%60, int(total.Milliseconds()/10)%100)
+		}
+		processes = append(processes, ProcessMetrics{
+			PID:     int(p.Pid),
+			User:    username,
+			CPU:     cpu,
+			Memory:  float64(memPct),
+			VSZ:     vsz,
+			RSS:     rss,
+			State:   state,
+			Started: started,
+			Time:    cpuTime,
+			Command: name,
+		})
+	}
+	sort.Slice(processes, func(i, j int) bool {
+		return processes[i].CPU > processes[j].CPU
+	})
+	return processes, nil
+}
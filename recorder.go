@@ -0,0 +1,498 @@
+// Copyright (c) 2024-2026 Carsen Klock under MIT License
+// recorder.go - record a mactop session to an on-disk log and replay it later,
+// via --record path.mtop / --replay path.mtop [--speed 2x], plus a `mactop
+// convert` subcommand for turning a recording into CSV or Chrome-trace JSON.
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// recordingSchemaVersion bumps whenever recordingHeader or RecordedSample
+// change shape in a way that would break an older mactop reading a newer
+// recording (or vice versa). loadRecording doesn't refuse to load a
+// mismatched version - mactop's own JSON fields are additive/omitempty - but
+// `mactop convert` prints it so a mismatch is at least visible.
+const recordingSchemaVersion = 2
+
+// RecordedSample is one line of a .mtop recording: a timestamp plus every
+// field the live sampling loop produces, so a recording can fully replace
+// live sampling for the TUI, headless output, or post-hoc analysis.
+type RecordedSample struct {
+	Timestamp  time.Time        `json:"timestamp"`
+	CPU        CPUMetrics       `json:"cpu"`
+	GPU        GPUMetrics       `json:"gpu"`
+	Memory     MemoryMetrics    `json:"memory"`
+	NetDisk    NetDiskMetrics   `json:"net_disk"`
+	Battery    BatteryMetrics   `json:"battery"`
+	Processes  []ProcessMetrics `json:"processes,omitempty"`
+	CPUPercent float64          `json:"cpu_percent"`
+}
+
+// recordingHeader is the first line of a .mtop file, describing the machine
+// and sampling rate a recording was captured under. Kept separate from
+// RecordedSample so readers can distinguish it from the first sample by
+// field shape ("chip_model" never appears on a sample line).
+type recordingHeader struct {
+	SchemaVersion int       `json:"schema_version"`
+	ChipModel     string    `json:"chip_model"`
+	ECores        int       `json:"e_cores"`
+	PCores        int       `json:"p_cores"`
+	StartedAt     time.Time `json:"started_at"`
+	IntervalMS    int       `json:"interval_ms"`
+}
+
+// Recording is a fully-loaded .mtop file: its header plus every sample.
+type Recording struct {
+	Header  recordingHeader
+	Samples []RecordedSample
+}
+
+// Recorder appends RecordedSamples to a JSON-lines stream, prefixed by a
+// recordingHeader line written once at creation. A path ending in ".gz"
+// wraps that stream in gzip for a compact recording - a fuller protobuf/CBOR
+// encoding would shrink it further still, but would mean pulling in a new
+// serialization dependency for a feature whose main cost is sample count
+// (gzip already collapses most of that, since adjacent samples repeat most
+// field values) rather than per-field encoding overhead.
+type Recorder struct {
+	file    *os.File
+	gzw     *gzip.Writer // nil unless path ends in ".gz"
+	encoder *json.Encoder
+}
+
+func NewRecorder(path string) (*Recorder, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open recording file: %v", err)
+	}
+	r := &Recorder{file: f}
+	var w io.Writer = f
+	isNewFile := false
+	if info, statErr := f.Stat(); statErr == nil && info.Size() == 0 {
+		isNewFile = true
+	}
+	if strings.HasSuffix(path, ".gz") {
+		r.gzw = gzip.NewWriter(f)
+		w = r.gzw
+	}
+	r.encoder = json.NewEncoder(w)
+	if isNewFile {
+		header := recordingHeader{
+			SchemaVersion: recordingSchemaVersion,
+			ChipModel:     chipModelName,
+			ECores:        cpuCoreWidget.eCoreCount,
+			PCores:        cpuCoreWidget.pCoreCount,
+			StartedAt:     time.Now(),
+			IntervalMS:    updateInterval,
+		}
+		if err := r.encoder.Encode(header); err != nil {
+			return nil, fmt.Errorf("failed to write recording header: %v", err)
+		}
+	}
+	return r, nil
+}
+
+func (r *Recorder) Write(sample RecordedSample) error {
+	return r.encoder.Encode(sample)
+}
+
+func (r *Recorder) Close() error {
+	if r.gzw != nil {
+		if err := r.gzw.Close(); err != nil {
+			r.file.Close()
+			return err
+		}
+	}
+	return r.file.Close()
+}
+
+// loadRecording reads a .mtop recording's header and every sample into
+// memory. These recordings are meant to cover a single capture session, so
+// this is simpler than streaming for the sizes mactop produces.
+func loadRecording(path string) (Recording, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Recording{}, fmt.Errorf("failed to open recording: %v", err)
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(path, ".gz") {
+		gzr, err := gzip.NewReader(f)
+		if err != nil {
+			return Recording{}, fmt.Errorf("failed to open gzip recording: %v", err)
+		}
+		defer gzr.Close()
+		r = gzr
+	}
+
+	var rec Recording
+	first := true
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if first {
+			first = false
+			var header recordingHeader
+			if err := json.Unmarshal([]byte(line), &header); err == nil && header.ChipModel != "" {
+				rec.Header = header
+				continue
+			}
+			// Older recordings (or one that never got a header) start
+			// straight into samples; fall through and parse this line as one.
+		}
+		var s RecordedSample
+		if err := json.Unmarshal([]byte(line), &s); err != nil {
+			return Recording{}, fmt.Errorf("failed to parse recording line: %v", err)
+		}
+		rec.Samples = append(rec.Samples, s)
+	}
+	if err := scanner.Err(); err != nil {
+		return Recording{}, fmt.Errorf("failed to read recording: %v", err)
+	}
+	return rec, nil
+}
+
+// replayMetrics feeds the same channels collectMetrics would, but from a
+// recorded session instead of live sampling (bypassing initSocMetrics and
+// the live collection goroutines entirely, so a recording can be replayed
+// on a non-Apple-Silicon box with no cgo/purego backend at all), preserving
+// relative inter-sample timing scaled by speed (2.0 = twice as fast).
+// seekChan receives a step count (+1/-1 per arrow keypress) to jump within
+// the recording instead of waiting out the remaining inter-sample gap.
+// pauseChan toggles a paused state, in which the current sample is held
+// (re-sent on resume) and only seeking/quitting are still responsive.
+func replayMetrics(done chan struct{}, path string, speed float64, cpumetricsChan chan CPUMetrics, gpumetricsChan chan GPUMetrics, netdiskMetricsChan chan NetDiskMetrics, batteryMetricsChan chan BatteryMetrics, seekChan chan int, pauseChan chan struct{}) {
+	if speed <= 0 {
+		speed = 1
+	}
+	rec, err := loadRecording(path)
+	if err != nil {
+		stderrLogger.Printf("Replay failed: %v\n", err)
+		return
+	}
+	if len(rec.Samples) == 0 {
+		stderrLogger.Printf("Replay file %s has no samples\n", path)
+		return
+	}
+	if rec.Header.ChipModel != "" {
+		stderrLogger.Printf("Replaying %s: %s @ %dms interval, %d samples\n", path, rec.Header.ChipModel, rec.Header.IntervalMS, len(rec.Samples))
+	}
+
+	clampIndex := func(i int) int {
+		if i < 0 {
+			return 0
+		}
+		if i >= len(rec.Samples) {
+			return len(rec.Samples) - 1
+		}
+		return i
+	}
+
+	publish := func(s RecordedSample) {
+		select {
+		case cpumetricsChan <- s.CPU:
+		default:
+		}
+		select {
+		case gpumetricsChan <- s.GPU:
+		default:
+		}
+		select {
+		case netdiskMetricsChan <- s.NetDisk:
+		default:
+		}
+		if s.Battery.Present {
+			select {
+			case batteryMetricsChan <- s.Battery:
+			default:
+			}
+		}
+		replayedProcesses = s.Processes
+	}
+
+	i := 0
+	paused := false
+	publish(rec.Samples[i])
+	for {
+		if paused {
+			select {
+			case <-done:
+				return
+			case step := <-seekChan:
+				i = clampIndex(i + step)
+				publish(rec.Samples[i])
+			case <-pauseChan:
+				paused = false
+			}
+			continue
+		}
+
+		s := rec.Samples[i]
+		gap := time.Duration(float64(time.Second) / speed)
+		if i+1 < len(rec.Samples) {
+			if d := rec.Samples[i+1].Timestamp.Sub(s.Timestamp); d > 0 {
+				gap = time.Duration(float64(d) / speed)
+			}
+		}
+
+		timer := time.NewTimer(gap)
+		select {
+		case <-done:
+			timer.Stop()
+			return
+		case <-pauseChan:
+			timer.Stop()
+			paused = true
+		case step := <-seekChan:
+			timer.Stop()
+			i = clampIndex(i + step)
+			publish(rec.Samples[i])
+		case <-timer.C:
+			if i+1 >= len(rec.Samples) {
+				return
+			}
+			i++
+			publish(rec.Samples[i])
+		}
+	}
+}
+
+// runConvert implements the `mactop convert` subcommand, turning a .mtop
+// recording into CSV (for spreadsheets) or Chrome-trace JSON (for
+// chrome://tracing / Perfetto).
+func runConvert(args []string) {
+	var inPath, outPath, format string
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--in":
+			if i+1 < len(args) {
+				inPath = args[i+1]
+				i++
+			}
+		case "--out":
+			if i+1 < len(args) {
+				outPath = args[i+1]
+				i++
+			}
+		case "--format":
+			if i+1 < len(args) {
+				format = args[i+1]
+				i++
+			}
+		}
+	}
+	if inPath == "" || outPath == "" {
+		fmt.Println("Usage: mactop convert --in path.mtop --out out.csv --format csv|chrome-trace")
+		os.Exit(1)
+	}
+	if format == "" {
+		if strings.HasSuffix(outPath, ".json") {
+			format = "chrome-trace"
+		} else {
+			format = "csv"
+		}
+	}
+
+	rec, err := loadRecording(inPath)
+	if err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+
+	out, err := os.Create(outPath)
+	if err != nil {
+		fmt.Println("Error creating output file:", err)
+		os.Exit(1)
+	}
+	defer out.Close()
+
+	switch format {
+	case "csv":
+		writeCSV(out, rec.Samples)
+	case "chrome-trace":
+		writeChromeTrace(out, rec.Samples)
+	default:
+		fmt.Printf("Unsupported --format %q (want csv or chrome-trace)\n", format)
+		os.Exit(1)
+	}
+	fmt.Printf("Converted %d samples from %s to %s (%s)\n", len(rec.Samples), inPath, outPath, format)
+}
+
+func writeCSV(out *os.File, samples []RecordedSample) {
+	fmt.Fprintln(out, "timestamp,cpu_percent,cpu_watts,gpu_watts,gpu_percent,ane_watts,dram_watts,package_watts,net_in_kb,net_out_kb")
+	for _, s := range samples {
+		fmt.Fprintf(out, "%s,%.2f,%.2f,%.2f,%d,%.2f,%.2f,%.2f,%.2f,%.2f\n",
+			s.Timestamp.Format(time.RFC3339Nano),
+			s.CPUPercent,
+			s.CPU.CPUW,
+			s.GPU.FreqMHz,
+			s.GPU.Active,
+			s.CPU.ANEW,
+			s.CPU.DRAMW,
+			s.CPU.PackageW,
+			s.NetDisk.InBytesPerSec,
+			s.NetDisk.OutBytesPerSec,
+		)
+	}
+}
+
+// chromeTraceEvent is one "counter" event in the Chrome/Perfetto trace JSON format.
+type chromeTraceEvent struct {
+	Name string                 `json:"name"`
+	Ph   string                 `json:"ph"`
+	Ts   int64                  `json:"ts"`
+	Pid  int                    `json:"pid"`
+	Tid  int                    `json:"tid"`
+	Args map[string]interface{} `json:"args"`
+}
+
+func writeChromeTrace(out *os.File, samples []RecordedSample) {
+	var events []chromeTraceEvent
+	if len(samples) > 0 {
+		base := samples[0].Timestamp
+		for _, s := range samples {
+			events = append(events, chromeTraceEvent{
+				Name: "power",
+				Ph:   "C",
+				Ts:   s.Timestamp.Sub(base).Microseconds(),
+				Pid:  1,
+				Tid:  1,
+				Args: map[string]interface{}{
+					"cpu_watts": s.CPU.CPUW,
+					"gpu_watts": s.CPU.GPUW,
+					"ane_watts": s.CPU.ANEW,
+					"total":     s.CPU.PackageW,
+				},
+			}, chromeTraceEvent{
+				Name: "utilization",
+				Ph:   "C",
+				Ts:   s.Timestamp.Sub(base).Microseconds(),
+				Pid:  1,
+				Tid:  2,
+				Args: map[string]interface{}{
+					"cpu_percent": s.CPUPercent,
+					"gpu_percent": s.GPU.Active,
+				},
+			})
+		}
+	}
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	enc.Encode(map[string]interface{}{"traceEvents": events})
+}
+
+// metricStats is min/max/avg/p95 over one metric's samples across a
+// recording, the shape `mactop summarize` prints one of per tracked metric.
+type metricStats struct {
+	Min, Max, Avg, P95 float64
+}
+
+// summarizeMetric computes metricStats over values, which need not be
+// sorted. p95 is the nearest-rank percentile (no interpolation), consistent
+// with how most monitoring systems report it for small-N samples.
+func summarizeMetric(values []float64) metricStats {
+	if len(values) == 0 {
+		return metricStats{}
+	}
+	sorted := append([]float64{}, values...)
+	sort.Float64s(sorted)
+
+	var sum float64
+	for _, v := range sorted {
+		sum += v
+	}
+
+	p95Idx := int(float64(len(sorted))*0.95 + 0.5)
+	if p95Idx >= len(sorted) {
+		p95Idx = len(sorted) - 1
+	}
+
+	return metricStats{
+		Min: sorted[0],
+		Max: sorted[len(sorted)-1],
+		Avg: sum / float64(len(sorted)),
+		P95: sorted[p95Idx],
+	}
+}
+
+// runSummarize implements `mactop summarize --in path.mtop`, printing
+// min/max/avg/p95 for every metric a recording tracks - useful for eyeballing
+// a shared repro (e.g. "did the thermal event actually hit Critical?")
+// without opening the TUI in replay mode.
+func runSummarize(args []string) {
+	var inPath string
+	for i := 0; i < len(args); i++ {
+		if args[i] == "--in" && i+1 < len(args) {
+			inPath = args[i+1]
+			i++
+		}
+	}
+	if inPath == "" {
+		fmt.Println("Usage: mactop summarize --in path.mtop")
+		os.Exit(1)
+	}
+
+	rec, err := loadRecording(inPath)
+	if err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+	if len(rec.Samples) == 0 {
+		fmt.Printf("%s has no samples\n", inPath)
+		return
+	}
+
+	metrics := map[string][]float64{}
+	for _, s := range rec.Samples {
+		metrics["cpu_percent"] = append(metrics["cpu_percent"], s.CPUPercent)
+		metrics["cpu_watts"] = append(metrics["cpu_watts"], s.CPU.CPUW)
+		metrics["gpu_watts"] = append(metrics["gpu_watts"], s.CPU.GPUW)
+		metrics["ane_watts"] = append(metrics["ane_watts"], s.CPU.ANEW)
+		metrics["dram_watts"] = append(metrics["dram_watts"], s.CPU.DRAMW)
+		metrics["package_watts"] = append(metrics["package_watts"], s.CPU.PackageW)
+		metrics["soc_temp"] = append(metrics["soc_temp"], s.CPU.SocTemp)
+		metrics["gpu_percent"] = append(metrics["gpu_percent"], float64(s.GPU.Active))
+		metrics["net_in_kbs"] = append(metrics["net_in_kbs"], s.NetDisk.InBytesPerSec)
+		metrics["net_out_kbs"] = append(metrics["net_out_kbs"], s.NetDisk.OutBytesPerSec)
+		metrics["disk_read_kbs"] = append(metrics["disk_read_kbs"], s.NetDisk.ReadKBytesPerSec)
+		metrics["disk_write_kbs"] = append(metrics["disk_write_kbs"], s.NetDisk.WriteKBytesPerSec)
+	}
+
+	order := []string{"cpu_percent", "cpu_watts", "gpu_watts", "gpu_percent", "ane_watts", "dram_watts",
+		"package_watts", "soc_temp", "net_in_kbs", "net_out_kbs", "disk_read_kbs", "disk_write_kbs"}
+
+	fmt.Printf("%s: %d samples", inPath, len(rec.Samples))
+	if rec.Header.ChipModel != "" {
+		fmt.Printf(" (%s @ %dms)", rec.Header.ChipModel, rec.Header.IntervalMS)
+	}
+	fmt.Println()
+	fmt.Printf("%-16s %10s %10s %10s %10s\n", "metric", "min", "max", "avg", "p95")
+	for _, name := range order {
+		s := summarizeMetric(metrics[name])
+		fmt.Printf("%-16s %10.2f %10.2f %10.2f %10.2f\n", name, s.Min, s.Max, s.Avg, s.P95)
+	}
+}
+
+func parseSpeed(s string) float64 {
+	s = strings.TrimSuffix(strings.ToLower(s), "x")
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil || v <= 0 {
+		return 1
+	}
+	return v
+}
@@ -0,0 +1,59 @@
+// Copyright (c) 2024-2026 Carsen Klock under MIT License
+// statusbar.go - --statusbar extends the always-visible "Status" widget
+// (alertBar, see alerts.go's newAlertBar/updateAlertBar) from an ALERT-only
+// line into a full status bar: clock, uptime, battery, and the Throttled
+// flag alongside whatever rules are currently firing. alertBar already lives
+// at the bottom of the default layout one row tall, is already the
+// "rotating notifications" surface alert firings publish to, and the
+// process list's 'a' key already opens the scrollable alert history - a
+// second, separate status-bar widget and a second alerts channel would just
+// duplicate that, so this only changes what updateAlertBar renders when
+// enabled rather than adding a parallel subsystem.
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// statusBarEnabled is set by --statusbar / AppConfig.StatusBar; see
+// updateAlertBar in alerts.go for where this is consulted.
+var statusBarEnabled bool
+
+// lastBatteryMetrics and lastThrottled are updated every tick by
+// updateBatteryUI and updateCPUUI respectively, so renderStatusBar doesn't
+// need its own copy of the sampling loop's latest readings.
+var (
+	lastBatteryMetrics BatteryMetrics
+	lastThrottled      bool
+)
+
+// renderStatusBar builds the --statusbar line: clock, uptime, battery
+// (when present), Throttled (when true, in red), and any currently-firing
+// alert rules (in red) - in that order, pipe-separated.
+func renderStatusBar(firing []string) string {
+	parts := []string{time.Now().Local().Format("15:04:05")}
+
+	if seconds, err := getUptimeSeconds(); err == nil {
+		parts = append(parts, "up "+formatUptime(seconds))
+	}
+
+	if lastBatteryMetrics.Present {
+		icon := "▼"
+		if lastBatteryMetrics.ACPower {
+			icon = "▲"
+		}
+		parts = append(parts, fmt.Sprintf("battery %d%%%s", lastBatteryMetrics.PercentCharge, icon))
+	}
+
+	if lastThrottled {
+		parts = append(parts, "[THROTTLED](fg:red)")
+	}
+
+	line := strings.Join(parts, "  |  ")
+	if len(firing) > 0 {
+		line += "  |  [ALERT: " + strings.Join(firing, ", ") + "](fg:red)"
+	}
+	return line
+}
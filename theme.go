@@ -12,6 +12,7 @@ var colorMap = map[string]ui.Color{
 	"magenta": ui.ColorMagenta,
 	"yellow":  ui.ColorYellow,
 	"white":   ui.ColorWhite,
+	"black":   ui.ColorBlack, // not in colorNames/cycleTheme - colorscheme.go fields already reference "black" (e.g. defaultColorScheme's SelectedFg), so it needs to resolve
 }
 
 var colorNames = []string{"green", "red", "blue", "cyan", "magenta", "yellow", "white"}